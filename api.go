@@ -5,26 +5,54 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
+	"net/url"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cli/safeexec"
 )
 
-func resolveRepository() (string, error) {
-	sout, eout, err := gh("repo", "view")
-	if err != nil {
-		if strings.Contains(eout.String(), "not a git repository") {
-			return "", errors.New("Try running this command from inside a git repository or with the -R flag")
+const defaultHost = "github.com"
+
+// repoRef identifies a repository, possibly on a GitHub Enterprise host.
+type repoRef struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// parseRepo accepts either OWNER/REPO or HOST/OWNER/REPO. Repositories given
+// without a host are assumed to live on host.
+func parseRepo(s, host string) (repoRef, error) {
+	parts := strings.Split(s, "/")
+	for _, p := range parts {
+		if p == "" {
+			parts = nil
+			break
 		}
-		return "", err
 	}
-	viewOut := strings.Split(sout.String(), "\n")[0]
-	repo := strings.TrimSpace(strings.Split(viewOut, ":")[1])
+	switch len(parts) {
+	case 2:
+		return repoRef{Host: host, Owner: parts[0], Name: parts[1]}, nil
+	case 3:
+		return repoRef{Host: parts[0], Owner: parts[1], Name: parts[2]}, nil
+	}
+	return repoRef{}, fmt.Errorf("expected OWNER/REPO or HOST/OWNER/REPO, got %q", s)
+}
+
+func (r repoRef) FullName() string {
+	return r.Owner + "/" + r.Name
+}
 
-	return repo, nil
+// String only includes the host when it isn't github.com.
+func (r repoRef) String() string {
+	if r.Host == "" || r.Host == defaultHost {
+		return r.FullName()
+	}
+	return r.Host + "/" + r.FullName()
 }
 
 // gh shells out to gh, returning STDOUT/STDERR and any error
@@ -48,43 +76,470 @@ func gh(args ...string) (sout, eout bytes.Buffer, err error) {
 	return
 }
 
-func getSHAs(repo string) ([]string, error) {
-	cmdArgs := []string{
-		"api",
-		fmt.Sprintf("repos/%s/commits", repo),
-		"--paginate",
-		"--cache", "24h",
-		"--jq", ".[]|.sha",
+// git shells out to git, returning STDOUT/STDERR and any error
+func git(args ...string) (sout, eout bytes.Buffer, err error) {
+	gitBin, err := safeexec.LookPath("git")
+	if err != nil {
+		err = fmt.Errorf("could not find git. Is it installed? error: %w", err)
+		return
+	}
+
+	cmd := exec.Command(gitBin, args...)
+	cmd.Stderr = &eout
+	cmd.Stdout = &sout
+
+	err = cmd.Run()
+	if err != nil {
+		err = fmt.Errorf("failed to run git. error: %w, stderr: %s", err, eout.String())
+		return
+	}
+
+	return
+}
+
+// commitRange narrows down which commits are used as ammo.
+type commitRange struct {
+	Ref   string
+	Since time.Time
+	Until time.Time
+	Max   int
+}
+
+func (r commitRange) IsEmpty() bool {
+	return r.Ref == "" && r.Since.IsZero() && r.Until.IsZero() && r.Max == 0
+}
+
+// parseDate accepts either a plain date or a full RFC 3339 timestamp.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse date %q, expected YYYY-MM-DD", s)
 	}
+	return t, nil
+}
 
-	sout, _, err := gh(cmdArgs...)
+func getSHAs(repo string, r commitRange, p pager) ([]string, error) {
+	ref, err := parseRepo(repo, defaultHost)
 	if err != nil {
-		return nil, fmt.Errorf("gh call failed: %w", err)
+		return nil, err
 	}
 
-	split := strings.Split(sout.String(), "\n")
+	query := url.Values{}
+	if r.Ref != "" {
+		query.Set("sha", r.Ref)
+	}
+	if !r.Since.IsZero() {
+		query.Set("since", r.Since.UTC().Format(time.RFC3339))
+	}
+	if !r.Until.IsZero() {
+		query.Set("until", r.Until.UTC().Format(time.RFC3339))
+	}
+	path := fmt.Sprintf("repos/%s/commits", ref.FullName())
 
 	out := []string{}
+	err = restPages(ref.Host, path, query, p, func(data []byte) (int, bool, error) {
+		var commits []struct {
+			SHA string
+		}
+		if err := json.Unmarshal(data, &commits); err != nil {
+			return 0, false, fmt.Errorf("failed to parse API response: %w", err)
+		}
+		for _, c := range commits {
+			out = append(out, c.SHA)
+		}
+		// stop as soon as we have enough
+		return len(commits), r.Max == 0 || len(out) < r.Max, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	for _, l := range split {
+	if r.Max > 0 && len(out) > r.Max {
+		out = out[:r.Max]
+	}
+
+	return out, nil
+}
+
+// resolveLocalRepository names the git repository in the current working
+// directory after its top level directory.
+func resolveLocalRepository() (string, error) {
+	sout, eout, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		if strings.Contains(eout.String(), "not a git repository") {
+			return "", errors.New("Try running this command from inside a git repository")
+		}
+		return "", err
+	}
+
+	return filepath.Base(strings.TrimSpace(sout.String())), nil
+}
+
+func getLocalSHAs(r commitRange) ([]string, error) {
+	args := []string{"log", "--format=%H"}
+	if r.Max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", r.Max))
+	}
+	if !r.Since.IsZero() {
+		args = append(args, "--since="+r.Since.Format(time.RFC3339))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, "--until="+r.Until.Format(time.RFC3339))
+	}
+	if r.Ref != "" {
+		args = append(args, r.Ref, "--")
+	}
+
+	sout, _, err := git(args...)
+	if err != nil {
+		return nil, fmt.Errorf("git call failed: %w", err)
+	}
+
+	return splitLines(sout.String()), nil
+}
+
+func getLocalSubjects() ([]issueEntry, error) {
+	sout, _, err := git("log", "--no-merges", "--format=%s%x00%an%x00%cI")
+	if err != nil {
+		return nil, fmt.Errorf("git call failed: %w", err)
+	}
+
+	out := []issueEntry{}
+
+	for _, l := range splitLines(sout.String()) {
+		fields := strings.Split(l, "\x00")
+		if len(fields) != 3 {
+			continue
+		}
+		createdAt, _ := time.Parse(time.RFC3339, fields[2])
+		out = append(out, issueEntry{
+			Title:     fields[0],
+			Author:    fields[1],
+			CreatedAt: createdAt,
+		})
+	}
+
+	return out, nil
+}
+
+func splitLines(s string) []string {
+	out := []string{}
+
+	for _, l := range strings.Split(s, "\n") {
 		if l == "" {
 			continue
 		}
 		out = append(out, l)
 	}
 
+	return out
+}
+
+// getOrgRepos lists the repositories in org on host that are worth playing in.
+func getOrgRepos(host, org string, p pager) ([]string, error) {
+	path := fmt.Sprintf("orgs/%s/repos", org)
+
+	out := []string{}
+	err := restPages(host, path, url.Values{}, p, func(data []byte) (int, bool, error) {
+		var repos []struct {
+			FullName  string `json:"full_name"`
+			Archived  bool
+			HasIssues bool `json:"has_issues"`
+		}
+		if err := json.Unmarshal(data, &repos); err != nil {
+			return 0, false, fmt.Errorf("failed to parse API response: %w", err)
+		}
+		for _, r := range repos {
+			if r.Archived || !r.HasIssues {
+				continue
+			}
+			ref, err := parseRepo(r.FullName, host)
+			if err != nil {
+				return 0, false, err
+			}
+			out = append(out, ref.String())
+		}
+		return len(repos), true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return out, nil
 }
 
-func getIssues(repo string) ([]string, error) {
+// issueFields selects what we need to know about an issue or pull request.
+const issueFields = `
+	number
+	title
+	createdAt
+	author {
+		login
+	}
+	labels(first: 20) {
+		nodes {
+			name
+		}
+	}
+	comments {
+		totalCount
+	}
+	reactions {
+		totalCount
+	}`
+
+type issueNode struct {
+	Number    int
+	Title     string
+	CreatedAt time.Time
+	Author    struct {
+		Login string
+	}
+	Labels struct {
+		Nodes []struct {
+			Name string
+		}
+	}
+	Comments struct {
+		TotalCount int
+	}
+	Reactions struct {
+		TotalCount int
+	}
+}
+
+func (n issueNode) entry() issueEntry {
+	labels := []string{}
+	for _, label := range n.Labels.Nodes {
+		labels = append(labels, label.Name)
+	}
+	return issueEntry{
+		Number:    n.Number,
+		Title:     n.Title,
+		Labels:    labels,
+		Author:    n.Author.Login,
+		CreatedAt: n.CreatedAt,
+		Comments:  n.Comments.TotalCount,
+		Reactions: n.Reactions.TotalCount,
+	}
+}
+
+const perPage = 100
+
+// pager controls how paginated queries are fetched.
+type pager struct {
+	// MaxPages caps how many pages are fetched; 0 means no cap.
+	MaxPages int
+	// OnPage, if set, is called after each page is fetched.
+	OnPage func()
+}
+
+func (p pager) more(page int) bool {
+	return p.MaxPages == 0 || page <= p.MaxPages
+}
+
+func (p pager) fetched() {
+	if p.OnPage != nil {
+		p.OnPage()
+	}
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+// graphQLPages runs a GraphQL query against host one page at a time,
+// handing the data of each page to fn, which reports where the next page
+// starts.
+func graphQLPages(host, query string, vars map[string]interface{}, p pager, fn func(data []byte) (pageInfo, error)) error {
+	c, err := clientFor(host)
+	if err != nil {
+		return err
+	}
+
+	cursor := ""
+	for page := 1; p.more(page); page++ {
+		pageVars := map[string]interface{}{}
+		for k, v := range vars {
+			pageVars[k] = v
+		}
+		if cursor != "" {
+			pageVars["endCursor"] = cursor
+		}
+
+		var data json.RawMessage
+		err := c.GraphQL(query, pageVars, &data)
+		if err != nil {
+			return err
+		}
+		p.fetched()
+
+		info, err := fn(data)
+		if err != nil {
+			return err
+		}
+		if !info.HasNextPage {
+			break
+		}
+		cursor = info.EndCursor
+	}
+
+	return nil
+}
+
+// restPages fetches a listing endpoint on host one page at a time, handing
+// the JSON of each page to fn, which reports how many items the page held and
+// whether it wants more. A short page signals the end.
+func restPages(host, path string, query url.Values, p pager, fn func(data []byte) (items int, more bool, err error)) error {
+	c, err := clientFor(host)
+	if err != nil {
+		return err
+	}
+
+	query.Set("per_page", strconv.Itoa(perPage))
+	for page := 1; p.more(page); page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var data json.RawMessage
+		err := c.REST(path, query, &data)
+		if err != nil {
+			return err
+		}
+		p.fetched()
+
+		items, more, err := fn(data)
+		if err != nil {
+			return err
+		}
+		if !more || items < perPage {
+			break
+		}
+	}
+
+	return nil
+}
+
+func repoVars(ref repoRef) map[string]interface{} {
+	return map[string]interface{}{
+		"owner": ref.Owner,
+		"repo":  ref.Name,
+	}
+}
+
+// issueFilter narrows down which issues and pull requests make up the board.
+type issueFilter struct {
+	Labels    []string
+	Assignee  string
+	Milestone string
+	Search    string
+}
+
+func (f issueFilter) IsEmpty() bool {
+	return len(f.Labels) == 0 && f.Assignee == "" && f.Milestone == "" && f.Search == ""
+}
+
+// Query builds a GitHub search query for open items of kind ("issue" or
+// "pr") in repo matching the filter.
+func (f issueFilter) Query(repo, kind string) string {
+	quote := func(s string) string {
+		if strings.ContainsAny(s, " \t") {
+			return fmt.Sprintf("%q", s)
+		}
+		return s
+	}
+	terms := []string{
+		"repo:" + repo,
+		"is:" + kind,
+		"is:open",
+	}
+	for _, label := range f.Labels {
+		terms = append(terms, "label:"+quote(label))
+	}
+	if f.Assignee != "" {
+		terms = append(terms, "assignee:"+quote(f.Assignee))
+	}
+	if f.Milestone != "" {
+		terms = append(terms, "milestone:"+quote(f.Milestone))
+	}
+	if f.Search != "" {
+		terms = append(terms, f.Search)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchIssues finds open issues or pull requests (per kind) in repo that
+// match filter using the search API, which caps results at 1000. Results are
+// handed to send a page at a time.
+func searchIssues(repo string, filter issueFilter, kind string, p pager, send func([]issueEntry)) error {
+	ref, err := parseRepo(repo, defaultHost)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		query SearchIssuesForMC($q: String!, $endCursor: String) {
+			search(query: $q, type: ISSUE, first: 100, after: $endCursor) {
+				nodes {
+					... on Issue {` + issueFields + `
+					}
+					... on PullRequest {` + issueFields + `
+					}
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}`
+
+	type Doc struct {
+		Search struct {
+			Nodes    []issueNode
+			PageInfo pageInfo
+		}
+	}
+
+	vars := map[string]interface{}{
+		"q": filter.Query(ref.FullName(), kind),
+	}
+	return graphQLPages(ref.Host, query, vars, p, func(data []byte) (pageInfo, error) {
+		var doc Doc
+		err := json.Unmarshal(data, &doc)
+		if err != nil {
+			return pageInfo{}, fmt.Errorf("failed to parse API response: %w", err)
+		}
+
+		page := []issueEntry{}
+		for _, node := range doc.Search.Nodes {
+			entry := node.entry()
+			entry.PullRequest = kind == "pr"
+			page = append(page, entry)
+		}
+		send(page)
+
+		return doc.Search.PageInfo, nil
+	})
+}
+
+// fetchIssues hands the open issues of repo to send a page at a time.
+func fetchIssues(repo string, filter issueFilter, p pager, send func([]issueEntry)) error {
+	if !filter.IsEmpty() {
+		return searchIssues(repo, filter, "issue", p, send)
+	}
+
 	query := `
 		query GetIssuesForMC($owner: String!, $repo: String!, $endCursor: String) {
 			repository(owner: $owner, name: $repo) {
 				hasIssuesEnabled
 				issues(first: 100, after: $endCursor, states: [OPEN]) {
-					nodes {
-						number
-						title
+					nodes {` + issueFields + `
 					}
 					pageInfo {
 						hasNextPage
@@ -93,64 +548,93 @@ func getIssues(repo string) ([]string, error) {
 				}
 			}
 		}`
-	parts := strings.Split(repo, "/")
-	owner := parts[0]
-	name := parts[1]
 
-	cmdArgs := []string{
-		"api", "graphql",
-		"--paginate",
-		"--cache", "24h",
-		"-f", fmt.Sprintf("query=%s", query),
-		"-f", fmt.Sprintf("owner=%s", owner),
-		"-f", fmt.Sprintf("repo=%s", name),
-		"--jq", ".[]",
-	}
-
-	sout, _, err := gh(cmdArgs...)
+	ref, err := parseRepo(repo, defaultHost)
 	if err != nil {
-		return nil, fmt.Errorf("gh call failed: %w", err)
+		return err
 	}
 
 	type Doc struct {
 		Repository struct {
 			HasIssuesEnabled bool
 			Issues           struct {
-				Nodes []struct {
-					Number int
-					Title  string
-				}
-				PageInfo struct {
-					HasNextPage bool
-					EndCursor   string
-				}
+				Nodes    []issueNode
+				PageInfo pageInfo
 			}
 		}
 	}
 
-	out := []string{}
-
-	dec := json.NewDecoder(strings.NewReader(sout.String()))
-	for {
+	return graphQLPages(ref.Host, query, repoVars(ref), p, func(data []byte) (pageInfo, error) {
 		var doc Doc
-
-		err := dec.Decode(&doc)
-		if err == io.EOF {
-			// all done
-			break
-		}
+		err := json.Unmarshal(data, &doc)
 		if err != nil {
-			log.Fatal(err)
+			return pageInfo{}, fmt.Errorf("failed to parse API response: %w", err)
 		}
 
 		if !doc.Repository.HasIssuesEnabled {
-			return nil, errors.New("can only play in repositories with issues enabled")
+			return pageInfo{}, &apiError{Kind: apiErrIssuesDisabled}
 		}
 
+		page := []issueEntry{}
 		for _, issue := range doc.Repository.Issues.Nodes {
-			out = append(out, fmt.Sprintf("#%d %s", issue.Number, issue.Title))
+			page = append(page, issue.entry())
 		}
+		send(page)
+
+		return doc.Repository.Issues.PageInfo, nil
+	})
+}
 
+// fetchPullRequests hands the open pull requests of repo to send a page at a
+// time.
+func fetchPullRequests(repo string, filter issueFilter, p pager, send func([]issueEntry)) error {
+	if !filter.IsEmpty() {
+		return searchIssues(repo, filter, "pr", p, send)
 	}
-	return out, nil
+
+	query := `
+		query GetPullRequestsForMC($owner: String!, $repo: String!, $endCursor: String) {
+			repository(owner: $owner, name: $repo) {
+				pullRequests(first: 100, after: $endCursor, states: [OPEN]) {
+					nodes {` + issueFields + `
+					}
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+				}
+			}
+		}`
+
+	ref, err := parseRepo(repo, defaultHost)
+	if err != nil {
+		return err
+	}
+
+	type Doc struct {
+		Repository struct {
+			PullRequests struct {
+				Nodes    []issueNode
+				PageInfo pageInfo
+			}
+		}
+	}
+
+	return graphQLPages(ref.Host, query, repoVars(ref), p, func(data []byte) (pageInfo, error) {
+		var doc Doc
+		err := json.Unmarshal(data, &doc)
+		if err != nil {
+			return pageInfo{}, fmt.Errorf("failed to parse API response: %w", err)
+		}
+
+		page := []issueEntry{}
+		for _, pr := range doc.Repository.PullRequests.Nodes {
+			entry := pr.entry()
+			entry.PullRequest = true
+			page = append(page, entry)
+		}
+		send(page)
+
+		return doc.Repository.PullRequests.PageInfo, nil
+	})
 }
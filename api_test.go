@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func makeIssues(n int) []issueEntry {
+	out := []issueEntry{}
+	for i := 1; i <= n; i++ {
+		out = append(out, issueEntry{
+			Number: i,
+			Title:  fmt.Sprintf("issue %d", i),
+		})
+	}
+	return out
+}
+
+func makeSHAs(n int) []string {
+	out := []string{}
+	for i := 0; i < n; i++ {
+		out = append(out, fmt.Sprintf("%040x", i))
+	}
+	return out
+}
+
+func countRequests(requests []string, prefix string) int {
+	n := 0
+	for _, r := range requests {
+		if strings.HasPrefix(r, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestFetchIssues(t *testing.T) {
+	f := newFakeGitHub(t, "vilmibm", "gh-mergeconflict")
+	createdAt := time.Date(2021, 9, 5, 12, 0, 0, 0, time.UTC)
+	f.Issues = []issueEntry{
+		{
+			Number:    1,
+			Title:     "it's broken",
+			Labels:    []string{"bug", "security"},
+			Author:    "octocat",
+			CreatedAt: createdAt,
+			Comments:  3,
+			Reactions: 7,
+		},
+		{Number: 2, Title: "add a thing", Labels: []string{}},
+	}
+
+	issues, err := ghSource{}.Issues(f.Repo())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(issues, f.Issues) {
+		t.Errorf("got %+v, want %+v", issues, f.Issues)
+	}
+}
+
+func TestFetchIssuesPaginates(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(250)
+
+	pages := 0
+	var got []issueEntry
+	err := ghSource{}.StreamIssues(f.Repo(), func(page []issueEntry) {
+		pages++
+		got = append(got, page...)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pages != 3 {
+		t.Errorf("got %d pages, want 3", pages)
+	}
+	if len(got) != 250 || got[0].Number != 1 || got[249].Number != 250 {
+		t.Errorf("got %d issues, want all 250 in order", len(got))
+	}
+	if n := countRequests(f.Requests(), "GetIssuesForMC"); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
+
+func TestFetchIssuesMaxPages(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(250)
+
+	fetched := 0
+	source := ghSource{Pager: pager{MaxPages: 2, OnPage: func() { fetched++ }}}
+	issues, err := source.Issues(f.Repo())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(issues) != 200 {
+		t.Errorf("got %d issues, want 200", len(issues))
+	}
+	if fetched != 2 {
+		t.Errorf("OnPage called %d times, want 2", fetched)
+	}
+}
+
+func TestFetchIssuesDisabled(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.IssuesDisabled = true
+
+	_, err := ghSource{}.Issues(f.Repo())
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Kind != apiErrIssuesDisabled {
+		t.Fatalf("got %v, want an issues disabled error", err)
+	}
+	if exitCode(err) != 6 {
+		t.Errorf("got exit code %d, want 6", exitCode(err))
+	}
+}
+
+func TestFetchIssuesNotFound(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+
+	_, err := ghSource{}.Issues(f.Host + "/cli/nope")
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Kind != apiErrNotFound {
+		t.Fatalf("got %v, want a not found error", err)
+	}
+}
+
+func TestFetchPullRequests(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.PageSize = 2
+	f.PullRequests = makeIssues(5)
+
+	prs, err := ghSource{}.PullRequests(f.Repo())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(prs) != 5 {
+		t.Fatalf("got %d pull requests, want 5", len(prs))
+	}
+	for _, pr := range prs {
+		if !pr.PullRequest {
+			t.Errorf("%s not marked as a pull request", pr)
+		}
+	}
+	if n := countRequests(f.Requests(), "GetPullRequestsForMC"); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
+
+func TestGetSHAsPaginates(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Commits = makeSHAs(230)
+
+	shas, err := ghSource{}.SHAs(f.Repo())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(shas, f.Commits) {
+		t.Errorf("got %d shas, want all %d in order", len(shas), len(f.Commits))
+	}
+	if n := countRequests(f.Requests(), "/api/v3/repos/cli/cli/commits"); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
+
+func TestGetSHAsExactPage(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Commits = makeSHAs(200)
+
+	shas, err := ghSource{}.SHAs(f.Repo())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(shas) != 200 {
+		t.Errorf("got %d shas, want 200", len(shas))
+	}
+}
+
+func TestGetSHAsMax(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Commits = makeSHAs(500)
+
+	shas, err := ghSource{Commits: commitRange{Max: 150}}.SHAs(f.Repo())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(shas, f.Commits[:150]) {
+		t.Errorf("got %d shas, want the first 150", len(shas))
+	}
+	if n := countRequests(f.Requests(), "/api/v3/repos/cli/cli/commits"); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		message string
+		kind    apiErrorKind
+	}{
+		{"unauthorized", http.StatusUnauthorized, "Bad credentials", apiErrAuth},
+		{"rate limited", http.StatusForbidden, "API rate limit exceeded", apiErrRateLimited},
+		{"server error", http.StatusBadGateway, "", apiErrUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeGitHub(t, "cli", "cli")
+			f.Status = tt.status
+			f.Message = tt.message
+
+			_, err := ghSource{}.SHAs(f.Repo())
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an apiError", err)
+			}
+			if apiErr.Kind != tt.kind {
+				t.Errorf("got kind %d, want %d", apiErr.Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestOrgNotFound(t *testing.T) {
+	f := newFakeGitHub(t, "cli", "cli")
+
+	_, err := getOrgRepos(f.Host, "nope", pager{})
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Kind != apiErrNotFound {
+		t.Fatalf("got %v, want a not found error", err)
+	}
+	if strings.Contains(err.Error(), "repository") {
+		t.Errorf("got %q, which blames a repository for a missing organization", err)
+	}
+	if !strings.Contains(err.Error(), "orgs/nope/repos") {
+		t.Errorf("got %q, want it to say what wasn't found", err)
+	}
+}
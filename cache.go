@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultCacheTTL = 24 * time.Hour
+
+var cacheDirname string = "mc-cache"
+
+func cacheDir() string {
+	return filepath.Join(stateDir(), cacheDirname)
+}
+
+// repoCacheDir is where cached data for repo lives. Repositories on
+// github.com and other hosts never share a directory.
+func repoCacheDir(repo string) (string, error) {
+	ref, err := parseRepo(repo, defaultHost)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir(), ref.Host, ref.Owner, ref.Name), nil
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time    `json:"fetchedAt"`
+	Query     string       `json:"query"`
+	Issues    []issueEntry `json:"issues,omitempty"`
+	SHAs      []string     `json:"shas,omitempty"`
+	// Complete is false when a page cap may have cut the fetch short.
+	Complete bool `json:"complete"`
+}
+
+// cachedSource keeps what another DataSource fetches on disk so repeat games
+// don't have to wait on (or spend quota on) the network.
+type cachedSource struct {
+	Source DataSource
+	TTL    time.Duration
+	// Refresh ignores cached data, though it still gets updated.
+	Refresh bool
+	// Offline never calls Source; cached data is used no matter how old.
+	Offline bool
+	// Exhausted holds the hosts with no API quota left. Source is never
+	// called for their repositories either, since every request would fail.
+	Exhausted map[string]bool
+	// IssueQuery and CommitQuery describe the shape of the queries Source
+	// runs, such as filters or commit ranges, so that differently shaped
+	// queries are cached separately.
+	IssueQuery  string
+	CommitQuery string
+	// MaxPages is the page cap Source fetches with, if any. What it fetches
+	// under a cap is cached as incomplete, which only a capped fetch will
+	// settle for.
+	MaxPages int
+}
+
+func (cs cachedSource) Issues(repo string) ([]issueEntry, error) {
+	return collectIssues(func(send func([]issueEntry)) error {
+		return cs.StreamIssues(repo, send)
+	})
+}
+
+func (cs cachedSource) PullRequests(repo string) ([]issueEntry, error) {
+	return collectIssues(func(send func([]issueEntry)) error {
+		return cs.StreamPullRequests(repo, send)
+	})
+}
+
+func (cs cachedSource) StreamIssues(repo string, send func([]issueEntry)) error {
+	return cs.stream(repo, "issues", send, func(send func([]issueEntry)) error {
+		return streamIssues(cs.Source, repo, send)
+	})
+}
+
+func (cs cachedSource) StreamPullRequests(repo string, send func([]issueEntry)) error {
+	markPRs := func(page []issueEntry) {
+		for i := range page {
+			page[i].PullRequest = true
+		}
+		send(page)
+	}
+	return cs.stream(repo, "pulls", markPRs, func(send func([]issueEntry)) error {
+		return streamPullRequests(cs.Source, repo, send)
+	})
+}
+
+func (cs cachedSource) SHAs(repo string) ([]string, error) {
+	path, cached, usable, err := cs.lookup(repo, "commits", cs.CommitQuery)
+	if err != nil {
+		return nil, err
+	}
+	if usable {
+		return cached.SHAs, nil
+	}
+
+	shas, err := cs.Source.SHAs(repo)
+	if err != nil {
+		if isRateLimited(err) && cached != nil {
+			return cached.SHAs, nil
+		}
+		return nil, err
+	}
+
+	cs.store(path, cs.CommitQuery, &cacheEntry{SHAs: shas, Complete: cs.MaxPages == 0})
+
+	return shas, nil
+}
+
+// stream passes pages from get on to send, caching them once they have all
+// arrived, unless cached issues can be sent instead.
+func (cs cachedSource) stream(repo, kind string, send func([]issueEntry), get func(send func([]issueEntry)) error) error {
+	path, cached, usable, err := cs.lookup(repo, kind, cs.IssueQuery)
+	if err != nil {
+		return err
+	}
+	if usable {
+		send(cached.Issues)
+		return nil
+	}
+
+	all := []issueEntry{}
+	err = get(func(page []issueEntry) {
+		all = append(all, page...)
+		send(page)
+	})
+	if err != nil {
+		// stale data is better than nothing, but only if we haven't already
+		// sent some fresh data
+		if isRateLimited(err) && cached != nil && len(all) == 0 {
+			send(cached.Issues)
+			return nil
+		}
+		return err
+	}
+
+	cs.store(path, cs.IssueQuery, &cacheEntry{Issues: all, Complete: cs.MaxPages == 0})
+
+	return nil
+}
+
+// lookup finds where data of kind for repo is cached and what's cached
+// there, if anything. usable is true when the cached data should be used
+// without fetching anything. Whatever is cached, complete or not, is left
+// for the caller to fall back on.
+func (cs cachedSource) lookup(repo, kind, query string) (path string, cached *cacheEntry, usable bool, err error) {
+	dir, err := repoCacheDir(repo)
+	if err != nil {
+		return "", nil, false, err
+	}
+	sum := sha256.Sum256([]byte(query))
+	path = filepath.Join(dir, fmt.Sprintf("%s-%x.json", kind, sum[:6]))
+
+	cached, cacheErr := readCacheEntry(path)
+	if cacheErr != nil {
+		cached = nil
+	}
+
+	if cs.Offline {
+		if cached == nil {
+			return "", nil, false, fmt.Errorf("no cached %s for %s; play once without --offline first", kind, repo)
+		}
+		return path, cached, true, nil
+	}
+	if ref, _ := parseRepo(repo, defaultHost); cs.Exhausted[ref.Host] {
+		if cached == nil {
+			return "", nil, false, &apiError{Kind: apiErrRateLimited, Err: fmt.Errorf("no API quota left on %s and no cached %s for %s", ref.Host, kind, repo)}
+		}
+		return path, cached, true, nil
+	}
+
+	fresh := cached != nil && time.Since(cached.FetchedAt) < cs.TTL
+	enough := cached != nil && (cached.Complete || cs.MaxPages > 0)
+	return path, cached, fresh && enough && !cs.Refresh, nil
+}
+
+func (cs cachedSource) store(path, query string, entry *cacheEntry) {
+	entry.FetchedAt = time.Now()
+	entry.Query = query
+	// failing to cache shouldn't stop anyone from playing
+	_ = writeCacheEntry(path, entry)
+}
+
+func readCacheEntry(path string) (*cacheEntry, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+	err = json.Unmarshal(content, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func writeCacheEntry(path string, entry *cacheEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+// clearCache removes cached data for repo, or for every repository if repo
+// is empty.
+func clearCache(repo string) error {
+	dir := cacheDir()
+	if repo != "" {
+		var err error
+		dir, err = repoCacheDir(repo)
+		if err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// useTempState points stateDir, and so the cache, at a directory of its own
+// for the rest of the test.
+func useTempState(t *testing.T) {
+	t.Helper()
+	old, had := os.LookupEnv(XDG_STATE_HOME)
+	os.Setenv(XDG_STATE_HOME, t.TempDir())
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(XDG_STATE_HOME, old)
+		} else {
+			os.Unsetenv(XDG_STATE_HOME)
+		}
+	})
+}
+
+func newCachedSource(maxPages int) cachedSource {
+	return cachedSource{
+		Source:   ghSource{Pager: pager{MaxPages: maxPages}},
+		TTL:      time.Hour,
+		MaxPages: maxPages,
+	}
+}
+
+func TestCacheReusesFreshData(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(3)
+	f.Commits = makeSHAs(5)
+
+	cs := newCachedSource(0)
+	for i := 0; i < 2; i++ {
+		issues, err := cs.Issues(f.Repo())
+		if err != nil || len(issues) != 3 {
+			t.Fatalf("got %d issues, %v; want 3", len(issues), err)
+		}
+		shas, err := cs.SHAs(f.Repo())
+		if err != nil || len(shas) != 5 {
+			t.Fatalf("got %d shas, %v; want 5", len(shas), err)
+		}
+	}
+
+	if n := countRequests(f.Requests(), "GetIssuesForMC"); n != 1 {
+		t.Errorf("fetched issues %d times, want 1", n)
+	}
+	if n := countRequests(f.Requests(), "/api/v3/repos/cli/cli/commits"); n != 1 {
+		t.Errorf("fetched commits %d times, want 1", n)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(3)
+
+	cs := newCachedSource(0)
+	if _, err := cs.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cs.TTL = 0
+	if _, err := cs.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if n := countRequests(f.Requests(), "GetIssuesForMC"); n != 2 {
+		t.Errorf("fetched issues %d times, want 2", n)
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(3)
+
+	cs := newCachedSource(0)
+	if _, err := cs.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f.Issues = makeIssues(4)
+	cs.Refresh = true
+	issues, err := cs.Issues(f.Repo())
+	if err != nil || len(issues) != 4 {
+		t.Fatalf("got %d issues, %v; want the 4 on the server", len(issues), err)
+	}
+
+	// what was refreshed is what gets cached
+	cs.Refresh = false
+	issues, err = cs.Issues(f.Repo())
+	if err != nil || len(issues) != 4 {
+		t.Errorf("got %d cached issues, %v; want 4", len(issues), err)
+	}
+	if n := countRequests(f.Requests(), "GetIssuesForMC"); n != 2 {
+		t.Errorf("fetched issues %d times, want 2", n)
+	}
+}
+
+func TestCacheOffline(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(3)
+	f.Commits = makeSHAs(5)
+
+	cs := newCachedSource(0)
+	if _, err := cs.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cs.SHAs(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	before := len(f.Requests())
+
+	cs.Offline = true
+	cs.TTL = 0
+	issues, err := cs.Issues(f.Repo())
+	if err != nil || len(issues) != 3 {
+		t.Errorf("got %d issues, %v; want the 3 cached however old", len(issues), err)
+	}
+	shas, err := cs.SHAs(f.Repo())
+	if err != nil || len(shas) != 5 {
+		t.Errorf("got %d shas, %v; want the 5 cached however old", len(shas), err)
+	}
+	if after := len(f.Requests()); after != before {
+		t.Errorf("made %d requests while offline", after-before)
+	}
+
+	if _, err := cs.Issues(f.Host + "/cli/uncached"); err == nil {
+		t.Error("expected an error for a repository that was never cached")
+	}
+}
+
+func TestCacheFallsBackWhenRateLimited(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(150)
+	f.Commits = makeSHAs(150)
+
+	// only an incomplete fetch is cached, which is still better than nothing
+	capped := newCachedSource(1)
+	if _, err := capped.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := capped.SHAs(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f.Status = http.StatusForbidden
+	f.Message = "API rate limit exceeded"
+	cs := newCachedSource(0)
+	issues, err := cs.Issues(f.Repo())
+	if err != nil || len(issues) != 100 {
+		t.Errorf("got %d issues, %v; want the 100 cached", len(issues), err)
+	}
+	shas, err := cs.SHAs(f.Repo())
+	if err != nil || len(shas) != 100 {
+		t.Errorf("got %d shas, %v; want the 100 cached", len(shas), err)
+	}
+}
+
+func TestCacheCappedFetches(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(150)
+
+	capped := newCachedSource(1)
+	if _, err := capped.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a capped fetch won't do for an uncapped game...
+	cs := newCachedSource(0)
+	issues, err := cs.Issues(f.Repo())
+	if err != nil || len(issues) != 150 {
+		t.Fatalf("got %d issues, %v; want all 150 fetched", len(issues), err)
+	}
+	if n := countRequests(f.Requests(), "GetIssuesForMC"); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+
+	// ...but a complete one does for a capped game
+	issues, err = capped.Issues(f.Repo())
+	if err != nil || len(issues) != 150 {
+		t.Errorf("got %d issues, %v; want all 150 cached", len(issues), err)
+	}
+	if n := countRequests(f.Requests(), "GetIssuesForMC"); n != 3 {
+		t.Errorf("made %d requests, want no more than 3", n)
+	}
+}
+
+func TestCacheOutOfQuota(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.Issues = makeIssues(3)
+
+	cs := newCachedSource(0)
+	if _, err := cs.Issues(f.Repo()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	before := len(f.Requests())
+
+	cs.TTL = 0
+	cs.Exhausted = map[string]bool{f.Host: true}
+	issues, err := cs.Issues(f.Repo())
+	if err != nil || len(issues) != 3 {
+		t.Errorf("got %d issues, %v; want the 3 cached however old", len(issues), err)
+	}
+	if after := len(f.Requests()); after != before {
+		t.Errorf("made %d requests with no quota left", after-before)
+	}
+
+	_, err = cs.SHAs(f.Repo())
+	if !isRateLimited(err) {
+		t.Errorf("got %v, want a rate limit error for uncached commits", err)
+	}
+}
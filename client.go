@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/safeexec"
+	"gopkg.in/yaml.v3"
+)
+
+// apiClient talks to the GitHub API on one host over HTTP.
+type apiClient struct {
+	// RESTURL is the root REST endpoints are resolved against.
+	RESTURL string
+	// GraphQLURL is where GraphQL queries are posted.
+	GraphQLURL string
+	Token      string
+	HTTP       *http.Client
+}
+
+var (
+	apiClientsMu sync.Mutex
+	// apiClients holds a client per host, created on first use.
+	apiClients = map[string]*apiClient{}
+)
+
+// clientFor returns the API client for host, authenticating with the same
+// token gh uses.
+func clientFor(host string) (*apiClient, error) {
+	apiClientsMu.Lock()
+	defer apiClientsMu.Unlock()
+
+	if c, ok := apiClients[host]; ok {
+		return c, nil
+	}
+
+	token, err := authToken(host)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &apiClient{
+		RESTURL:    "https://api.github.com/",
+		GraphQLURL: "https://api.github.com/graphql",
+		Token:      token,
+		HTTP:       &http.Client{Timeout: 30 * time.Second},
+	}
+	if host != defaultHost {
+		c.RESTURL = fmt.Sprintf("https://%s/api/v3/", host)
+		c.GraphQLURL = fmt.Sprintf("https://%s/api/graphql", host)
+	}
+	apiClients[host] = c
+
+	return c, nil
+}
+
+// authToken finds a token for host the way gh does: from the environment,
+// then gh's hosts.yml, then by asking gh itself in case the token lives in
+// the system keyring.
+func authToken(host string) (string, error) {
+	envVars := []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	if host != defaultHost {
+		envVars = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, v := range envVars {
+		if token := os.Getenv(v); token != "" {
+			return token, nil
+		}
+	}
+
+	if token := ghHosts()[host].OAuthToken; token != "" {
+		return token, nil
+	}
+
+	if _, err := safeexec.LookPath("gh"); err == nil {
+		sout, _, err := gh("auth", "token", "--hostname", host)
+		if err == nil && strings.TrimSpace(sout.String()) != "" {
+			return strings.TrimSpace(sout.String()), nil
+		}
+	}
+
+	return "", &apiError{Kind: apiErrAuth, Err: fmt.Errorf("no token found for %s", host)}
+}
+
+type ghHost struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+// ghHosts reads the hosts gh has been authenticated with from its hosts.yml,
+// returning nothing if it can't.
+func ghHosts() map[string]ghHost {
+	hosts := map[string]ghHost{}
+	content, err := ioutil.ReadFile(filepath.Join(configDir(), "hosts.yml"))
+	if err != nil {
+		return hosts
+	}
+	if err := yaml.Unmarshal(content, &hosts); err != nil {
+		return map[string]ghHost{}
+	}
+	return hosts
+}
+
+var secondaryRateLimitBackoff = []time.Duration{
+	2 * time.Second,
+	4 * time.Second,
+	8 * time.Second,
+}
+
+// do sends a request, retrying ones that trip a secondary rate limit after
+// backing off, and decodes a successful JSON response into out.
+func (c *apiClient) do(method, u string, body []byte, out interface{}) error {
+	err := c.try(method, u, body, out)
+	for i := 0; isSecondaryRateLimit(err) && i < len(secondaryRateLimitBackoff); i++ {
+		time.Sleep(secondaryRateLimitBackoff[i])
+		err = c.try(method, u, body, out)
+	}
+	return err
+}
+
+func (c *apiClient) try(method, u string, body []byte, out interface{}) error {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "gh-mergeconflict")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return &apiError{Kind: apiErrUnknown, Err: err}
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return &apiError{Kind: apiErrUnknown, Err: err}
+	}
+
+	if resp.StatusCode >= 300 {
+		return httpError(resp, content)
+	}
+
+	if err := json.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return nil
+}
+
+// httpError turns an unsuccessful response into an apiError.
+func httpError(resp *http.Response, body []byte) error {
+	var payload struct {
+		Message string
+	}
+	_ = json.Unmarshal(body, &payload)
+	msg := payload.Message
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	err := fmt.Errorf("HTTP %d: %s (%s)", resp.StatusCode, msg, resp.Request.URL)
+
+	kind := apiErrUnknown
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		kind = apiErrAuth
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.Header.Get("X-RateLimit-Remaining") == "0",
+		strings.Contains(strings.ToLower(msg), "rate limit"):
+		kind = apiErrRateLimited
+	case resp.StatusCode == http.StatusNotFound:
+		kind = apiErrNotFound
+	}
+	return &apiError{Kind: kind, Err: err}
+}
+
+// graphQLError is an entry in the errors list of a GraphQL response.
+type graphQLError struct {
+	Type    string
+	Message string
+}
+
+// GraphQL runs query with vars and decodes the data it returns into data.
+func (c *apiClient) GraphQL(query string, vars map[string]interface{}, data interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": vars,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data   json.RawMessage
+		Errors []graphQLError
+	}
+	err = c.do("POST", c.GraphQLURL, body, &resp)
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		msgs := []string{}
+		kind := apiErrUnknown
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+			switch e.Type {
+			case "NOT_FOUND":
+				kind = apiErrNotFound
+			case "RATE_LIMITED":
+				kind = apiErrRateLimited
+			}
+		}
+		return &apiError{Kind: kind, Err: errors.New(strings.Join(msgs, "; "))}
+	}
+
+	if err := json.Unmarshal(resp.Data, data); err != nil {
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return nil
+}
+
+// REST fetches path, which is relative to the API root, and decodes the
+// response into data.
+func (c *apiClient) REST(path string, query url.Values, data interface{}) error {
+	base, err := url.Parse(c.RESTURL)
+	if err != nil {
+		return err
+	}
+	u, err := base.Parse(path)
+	if err != nil {
+		return err
+	}
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	return c.do("GET", u.String(), nil, data)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	GH_CONFIG_DIR   = "GH_CONFIG_DIR"
+	XDG_CONFIG_HOME = "XDG_CONFIG_HOME"
+	APP_DATA        = "AppData"
+)
+
+var configFilename string = "mc-config.yml"
+
+// Config path precedence
+// 1. GH_CONFIG_DIR
+// 2. XDG_CONFIG_HOME
+// 3. AppData (windows only)
+// 4. HOME
+func configDir() string {
+	var path string
+	if a := os.Getenv(GH_CONFIG_DIR); a != "" {
+		path = a
+	} else if b := os.Getenv(XDG_CONFIG_HOME); b != "" {
+		path = filepath.Join(b, "gh")
+	} else if c := os.Getenv(APP_DATA); runtime.GOOS == "windows" && c != "" {
+		path = filepath.Join(c, "GitHub CLI")
+	} else {
+		d, _ := os.UserHomeDir()
+		path = filepath.Join(d, ".config", "gh")
+	}
+
+	return path
+}
+
+// labelBehavior changes how an issue carrying a given label plays.
+type labelBehavior struct {
+	// Speed multiplies how many cells the issue moves per tick.
+	Speed float64
+	// Value multiplies the points earned per letter shot.
+	Value float64
+	// Flash makes the issue blink so it stands out.
+	Flash bool
+	// Penalty is the number of points lost if the issue escapes.
+	Penalty int
+	// Indestructible issues can be hit but never lose letters.
+	Indestructible bool
+}
+
+var defaultLabelBehaviors = map[string]labelBehavior{
+	"bug":              {Speed: 2},
+	"good first issue": {Speed: 0.5, Value: 0.5},
+	"security":         {Flash: true, Penalty: 10},
+	"wontfix":          {Indestructible: true},
+}
+
+type repoConfig struct {
+	Labels map[string]labelBehavior
+}
+
+type configEntry struct {
+	Labels       map[string]labelBehavior
+	Repos        map[string]repoConfig
+	Difficulty   string
+	Difficulties map[string]difficultyOverride
+}
+
+func (g *Game) LoadConfig() error {
+	configFilePath := filepath.Join(configDir(), configFilename)
+
+	g.Debugf("opening %s", configFilePath)
+
+	g.Config = &configEntry{}
+
+	content, err := ioutil.ReadFile(configFilePath)
+	if err == nil {
+		g.Debugf("read: %s", content)
+		err = yaml.Unmarshal(content, g.Config)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", configFilePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", configFilePath, err)
+	}
+
+	g.Debugf("%#v", g.Config)
+
+	return nil
+}
+
+// LabelBehaviors returns the label mapping in effect for repo. Repository
+// specific entries take precedence over global ones, which take precedence
+// over the built in defaults.
+func (g *Game) LabelBehaviors(repo string) map[string]labelBehavior {
+	out := map[string]labelBehavior{}
+	for k, v := range defaultLabelBehaviors {
+		out[k] = v
+	}
+	if g.Config == nil {
+		return out
+	}
+	for k, v := range g.Config.Labels {
+		out[strings.ToLower(k)] = v
+	}
+	for k, v := range g.Config.Repos[repo].Labels {
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
+
+// BehaviorFor combines the behaviors of every label on an issue.
+func (g *Game) BehaviorFor(issue issueEntry) labelBehavior {
+	out := labelBehavior{Speed: 1, Value: 1}
+	repo := issue.Repo
+	if repo == "" {
+		repo = g.Repo
+	}
+	behaviors := g.LabelBehaviors(repo)
+	for _, label := range issue.Labels {
+		b, ok := behaviors[strings.ToLower(label)]
+		if !ok {
+			continue
+		}
+		if b.Speed > 0 {
+			out.Speed *= b.Speed
+		}
+		if b.Value > 0 {
+			out.Value *= b.Value
+		}
+		out.Flash = out.Flash || b.Flash
+		out.Penalty += b.Penalty
+		out.Indestructible = out.Indestructible || b.Indestructible
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigReportsBadConfig(t *testing.T) {
+	dir := t.TempDir()
+	old, had := os.LookupEnv(GH_CONFIG_DIR)
+	os.Setenv(GH_CONFIG_DIR, dir)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(GH_CONFIG_DIR, old)
+		} else {
+			os.Unsetenv(GH_CONFIG_DIR)
+		}
+	})
+
+	game := &Game{}
+	if err := game.LoadConfig(); err != nil {
+		t.Fatalf("got %v without a config file, want no error", err)
+	}
+
+	for _, content := range []string{
+		"difficulties:\n  hard:\n    frameDelay: fast\n",
+		"labels: [bug]\n",
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, configFilename), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := game.LoadConfig(); err == nil {
+			t.Errorf("expected an error for config %q", content)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// difficulty tunes how fast the game plays.
+type difficulty struct {
+	// FrameDelay is how long each frame lasts.
+	FrameDelay time.Duration `json:"frameDelay" yaml:"frameDelay"`
+	// IssueSpeed multiplies how many cells issues move per frame.
+	IssueSpeed float64 `json:"issueSpeed" yaml:"issueSpeed"`
+	// SpawnCooldown is how many frames a spawner rests after an issue has
+	// fully left it, however long that took at the issue's speed.
+	SpawnCooldown int `json:"spawnCooldown" yaml:"spawnCooldown"`
+	// LauncherCooldown is how many frames the launcher needs between shots.
+	LauncherCooldown int `json:"launcherCooldown" yaml:"launcherCooldown"`
+	// BacklogHealth is how many letters of escaped issues the backlog can
+	// take before the game is lost.
+	BacklogHealth int `json:"backlogHealth" yaml:"backlogHealth"`
+}
+
+// difficultyOverride tweaks a preset from the config file. Fields left out
+// keep the preset's value, so zero can still be set explicitly.
+type difficultyOverride struct {
+	FrameDelay       *time.Duration `json:"frameDelay,omitempty" yaml:"frameDelay"`
+	IssueSpeed       *float64       `json:"issueSpeed,omitempty" yaml:"issueSpeed"`
+	SpawnCooldown    *int           `json:"spawnCooldown,omitempty" yaml:"spawnCooldown"`
+	LauncherCooldown *int           `json:"launcherCooldown,omitempty" yaml:"launcherCooldown"`
+	BacklogHealth    *int           `json:"backlogHealth,omitempty" yaml:"backlogHealth"`
+}
+
+const defaultDifficulty = "normal"
+
+var difficultyNames = []string{"easy", "normal", "hard", "nightmare"}
+
+var difficultyPresets = map[string]difficulty{
+	"easy": {
+		FrameDelay:       120 * time.Millisecond,
+		IssueSpeed:       0.75,
+		SpawnCooldown:    8,
+		LauncherCooldown: 3,
+		BacklogHealth:    1000,
+	},
+	"normal": {
+		FrameDelay:       frameDelay,
+		IssueSpeed:       1,
+		SpawnCooldown:    3,
+		LauncherCooldown: 4,
+		BacklogHealth:    500,
+	},
+	"hard": {
+		FrameDelay:       80 * time.Millisecond,
+		IssueSpeed:       1.5,
+		SpawnCooldown:    1,
+		LauncherCooldown: 5,
+		BacklogHealth:    300,
+	},
+	"nightmare": {
+		FrameDelay:       60 * time.Millisecond,
+		IssueSpeed:       2,
+		SpawnCooldown:    0,
+		LauncherCooldown: 6,
+		BacklogHealth:    150,
+	},
+}
+
+func validDifficulty(name string) error {
+	if _, ok := difficultyPresets[name]; !ok {
+		return fmt.Errorf("unknown difficulty %q, expected one of %s", name, strings.Join(difficultyNames, ", "))
+	}
+	return nil
+}
+
+// ResolveDifficulty looks up the named preset, or the configured default if
+// name is empty, applying any overrides from the config file.
+func (c *configEntry) ResolveDifficulty(name string) (string, difficulty, error) {
+	if name == "" && c != nil {
+		name = c.Difficulty
+	}
+	if name == "" {
+		name = defaultDifficulty
+	}
+	if err := validDifficulty(name); err != nil {
+		return "", difficulty{}, err
+	}
+
+	d := difficultyPresets[name]
+	if c == nil {
+		return name, d, nil
+	}
+	override, ok := c.Difficulties[name]
+	if !ok {
+		return name, d, nil
+	}
+	if override.FrameDelay != nil {
+		if *override.FrameDelay <= 0 {
+			return "", difficulty{}, fmt.Errorf("frameDelay for %s must be positive", name)
+		}
+		d.FrameDelay = *override.FrameDelay
+	}
+	if override.IssueSpeed != nil {
+		if *override.IssueSpeed <= 0 {
+			return "", difficulty{}, fmt.Errorf("issueSpeed for %s must be positive", name)
+		}
+		d.IssueSpeed = *override.IssueSpeed
+	}
+	if override.SpawnCooldown != nil {
+		if *override.SpawnCooldown < 0 {
+			return "", difficulty{}, fmt.Errorf("spawnCooldown for %s can't be negative", name)
+		}
+		d.SpawnCooldown = *override.SpawnCooldown
+	}
+	if override.LauncherCooldown != nil {
+		if *override.LauncherCooldown < 0 {
+			return "", difficulty{}, fmt.Errorf("launcherCooldown for %s can't be negative", name)
+		}
+		d.LauncherCooldown = *override.LauncherCooldown
+	}
+	if override.BacklogHealth != nil {
+		if *override.BacklogHealth <= 0 {
+			return "", difficulty{}, fmt.Errorf("backlogHealth for %s must be positive", name)
+		}
+		d.BacklogHealth = *override.BacklogHealth
+	}
+	return name, d, nil
+}
+
+// difficultyKey keeps high scores for each difficulty apart. Normal games
+// keep using the plain key so older high scores still count.
+func difficultyKey(key, name string) string {
+	if name == "" || name == defaultDifficulty {
+		return key
+	}
+	return fmt.Sprintf("%s (%s)", key, name)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveDifficulty(t *testing.T) {
+	config := &configEntry{}
+	err := yaml.Unmarshal([]byte(`
+difficulty: hard
+difficulties:
+  hard:
+    frameDelay: 70ms
+    spawnCooldown: 0
+`), config)
+	if err != nil {
+		t.Fatalf("failed to parse config: %s", err)
+	}
+
+	name, d, err := config.ResolveDifficulty("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if name != "hard" {
+		t.Errorf("got %q, want the configured default", name)
+	}
+	want := difficultyPresets["hard"]
+	want.FrameDelay = 70 * time.Millisecond
+	want.SpawnCooldown = 0
+	if d != want {
+		t.Errorf("got %+v, want %+v", d, want)
+	}
+
+	name, d, err = config.ResolveDifficulty("easy")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if name != "easy" || d != difficultyPresets["easy"] {
+		t.Errorf("got %q %+v, want the easy preset", name, d)
+	}
+
+	if _, _, err := config.ResolveDifficulty("impossible"); err == nil {
+		t.Error("expected an error for an unknown difficulty")
+	}
+
+	speed := 0.0
+	config.Difficulties["easy"] = difficultyOverride{IssueSpeed: &speed}
+	if _, _, err := config.ResolveDifficulty("easy"); err == nil {
+		t.Error("expected an error for issues that never move")
+	}
+
+	name, _, err = (*configEntry)(nil).ResolveDifficulty("")
+	if err != nil || name != defaultDifficulty {
+		t.Errorf("got %q, %v without a config, want %q", name, err, defaultDifficulty)
+	}
+}
+
+func TestDifficultyKey(t *testing.T) {
+	if got := difficultyKey("cli/cli", "normal"); got != "cli/cli" {
+		t.Errorf("got %q, want normal games to keep the plain key", got)
+	}
+	if got := difficultyKey("cli/cli", "nightmare"); got != "cli/cli (nightmare)" {
+		t.Errorf("got %q", got)
+	}
+}
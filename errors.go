@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type apiErrorKind int
+
+const (
+	apiErrUnknown apiErrorKind = iota
+	apiErrAuth
+	apiErrRateLimited
+	apiErrNotFound
+	apiErrIssuesDisabled
+)
+
+// apiError is a failure talking to GitHub that we know how to explain.
+type apiError struct {
+	Kind apiErrorKind
+	Err  error
+}
+
+func (e *apiError) Error() string {
+	var msg string
+	switch e.Kind {
+	case apiErrAuth:
+		msg = "not authenticated with GitHub"
+	case apiErrRateLimited:
+		msg = "GitHub API rate limit exceeded"
+	case apiErrNotFound:
+		msg = "not found"
+	case apiErrIssuesDisabled:
+		msg = "issues are disabled"
+	default:
+		msg = "GitHub API request failed"
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *apiError) Unwrap() error {
+	return e.Err
+}
+
+// Hint suggests what to do about the error.
+func (e *apiError) Hint() string {
+	switch e.Kind {
+	case apiErrAuth:
+		return "run `gh auth login` or set GH_TOKEN and try again"
+	case apiErrRateLimited:
+		return "wait for your rate limit to reset, or play offline with --local or --data"
+	case apiErrNotFound:
+		return "check the spelling of the repository, organization or --ref and that you have access to them"
+	case apiErrIssuesDisabled:
+		return "can only play in repositories with issues enabled"
+	}
+	return ""
+}
+
+// ExitCode gives each kind of error its own exit status; 4 for auth matches
+// what gh itself uses.
+func (e *apiError) ExitCode() int {
+	switch e.Kind {
+	case apiErrAuth:
+		return 4
+	case apiErrNotFound:
+		return 5
+	case apiErrIssuesDisabled:
+		return 6
+	case apiErrRateLimited:
+		return 7
+	}
+	return 1
+}
+
+// exitCode picks the process exit status for err.
+func exitCode(err error) int {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub is a local stand-in for the parts of the GitHub API the game
+// uses. It serves one repository's issues and pull requests over GraphQL and
+// its commits over REST, paginating both.
+type fakeGitHub struct {
+	Owner string
+	Name  string
+
+	Issues         []issueEntry
+	PullRequests   []issueEntry
+	Commits        []string
+	IssuesDisabled bool
+	// PageSize is how many issues a GraphQL page holds; it defaults to 100.
+	PageSize int
+	// Status, if set, is returned for every request along with Message.
+	Status  int
+	Message string
+
+	// Host is what to prefix repositories with to reach the server.
+	Host string
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newFakeGitHub starts a fake server for OWNER/REPO and points the API client
+// for a host unique to the test at it.
+func newFakeGitHub(t *testing.T, owner, name string) *fakeGitHub {
+	t.Helper()
+
+	f := &fakeGitHub{
+		Owner: owner,
+		Name:  name,
+		Host:  strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-")) + ".example.com",
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	apiClientsMu.Lock()
+	apiClients[f.Host] = &apiClient{
+		RESTURL:    srv.URL + "/api/v3/",
+		GraphQLURL: srv.URL + "/api/graphql",
+		HTTP:       srv.Client(),
+	}
+	apiClientsMu.Unlock()
+	t.Cleanup(func() {
+		apiClientsMu.Lock()
+		delete(apiClients, f.Host)
+		apiClientsMu.Unlock()
+	})
+
+	return f
+}
+
+// Repo is the repository the server hosts as the game names it.
+func (f *fakeGitHub) Repo() string {
+	return repoRef{Host: f.Host, Owner: f.Owner, Name: f.Name}.String()
+}
+
+// Requests lists the requests served so far, GraphQL ones by operation name.
+func (f *fakeGitHub) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.requests...)
+}
+
+func (f *fakeGitHub) record(req string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if f.Status != 0 {
+		f.record(r.URL.Path)
+		w.WriteHeader(f.Status)
+		writeJSON(w, map[string]string{"message": f.Message})
+		return
+	}
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/api/graphql":
+		f.serveGraphQL(w, r)
+	case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v3/repos/%s/%s/commits", f.Owner, f.Name):
+		f.record(r.URL.Path + "?" + r.URL.RawQuery)
+		f.serveCommits(w, r)
+	default:
+		f.record(r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]string{"message": "Not Found"})
+	}
+}
+
+func (f *fakeGitHub) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"message": err.Error()})
+		return
+	}
+
+	op := strings.Fields(strings.TrimSpace(req.Query))[1]
+	if i := strings.Index(op, "("); i >= 0 {
+		op = op[:i]
+	}
+	f.record(op)
+
+	if req.Variables["owner"] != f.Owner || req.Variables["repo"] != f.Name {
+		writeJSON(w, map[string]interface{}{
+			"data": map[string]interface{}{"repository": nil},
+			"errors": []map[string]string{{
+				"type":    "NOT_FOUND",
+				"message": fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", req.Variables["owner"], req.Variables["repo"]),
+			}},
+		})
+		return
+	}
+
+	cursor, _ := req.Variables["endCursor"].(string)
+	switch op {
+	case "GetIssuesForMC":
+		if f.IssuesDisabled {
+			writeJSON(w, map[string]interface{}{
+				"data": map[string]interface{}{
+					"repository": map[string]interface{}{
+						"hasIssuesEnabled": false,
+						"issues":           f.connection(nil, ""),
+					},
+				},
+			})
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"repository": map[string]interface{}{
+					"hasIssuesEnabled": true,
+					"issues":           f.connection(f.Issues, cursor),
+				},
+			},
+		})
+	case "GetPullRequestsForMC":
+		writeJSON(w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"repository": map[string]interface{}{
+					"pullRequests": f.connection(f.PullRequests, cursor),
+				},
+			},
+		})
+	default:
+		writeJSON(w, map[string]interface{}{
+			"errors": []map[string]string{{"message": "unsupported query " + op}},
+		})
+	}
+}
+
+// connection serves the page of entries after cursor, which is the index of
+// the last entry on the previous page.
+func (f *fakeGitHub) connection(entries []issueEntry, cursor string) map[string]interface{} {
+	pageSize := f.PageSize
+	if pageSize == 0 {
+		pageSize = 100
+	}
+	start := 0
+	if cursor != "" {
+		n, _ := strconv.Atoi(cursor)
+		start = n + 1
+	}
+	end := start + pageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	nodes := []map[string]interface{}{}
+	for _, e := range entries[start:end] {
+		labels := []map[string]string{}
+		for _, l := range e.Labels {
+			labels = append(labels, map[string]string{"name": l})
+		}
+		nodes = append(nodes, map[string]interface{}{
+			"number":    e.Number,
+			"title":     e.Title,
+			"createdAt": e.CreatedAt,
+			"author":    map[string]string{"login": e.Author},
+			"labels":    map[string]interface{}{"nodes": labels},
+			"comments":  map[string]int{"totalCount": e.Comments},
+			"reactions": map[string]int{"totalCount": e.Reactions},
+		})
+	}
+
+	return map[string]interface{}{
+		"nodes": nodes,
+		"pageInfo": map[string]interface{}{
+			"hasNextPage": end < len(entries),
+			"endCursor":   strconv.Itoa(end - 1),
+		},
+	}
+}
+
+func (f *fakeGitHub) serveCommits(w http.ResponseWriter, r *http.Request) {
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage == 0 {
+		perPage = 30
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	start := (page - 1) * perPage
+	if start > len(f.Commits) {
+		start = len(f.Commits)
+	}
+	end := start + perPage
+	if end > len(f.Commits) {
+		end = len(f.Commits)
+	}
+
+	commits := []map[string]string{}
+	for _, sha := range f.Commits[start:end] {
+		commits = append(commits, map[string]string{"sha": sha})
+	}
+	writeJSON(w, commits)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
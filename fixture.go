@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fixture is a portable snapshot of the issues, pull requests and commits of
+// a repository.
+// Fixture files may be written as YAML or JSON.
+type fixture struct {
+	Repository   string       `json:"repository" yaml:"repository"`
+	Issues       []issueEntry `json:"issues" yaml:"issues"`
+	PullRequests []issueEntry `json:"pullRequests,omitempty" yaml:"pullRequests,omitempty"`
+	Commits      []string     `json:"commits" yaml:"commits"`
+}
+
+func loadFixture(path string) (*fixture, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	// JSON is valid YAML, so one decoder covers both formats.
+	var f fixture
+	err = yaml.Unmarshal(content, &f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+	}
+
+	return &f, nil
+}
+
+// writeFixture serializes f to path, or to STDOUT if path is empty. Files
+// ending in .json are written as JSON, everything else as YAML.
+func writeFixture(path string, f *fixture) error {
+	var content []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		content, err = json.MarshalIndent(f, "", "  ")
+		content = append(content, '\n')
+	} else {
+		content, err = yaml.Marshal(f)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to serialize data: %w", err)
+	}
+
+	if path == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+
+	err = os.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write data file: %w", err)
+	}
+
+	return nil
+}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -69,7 +72,8 @@ func (g *GameObject) Draw() {
 type Direction int // either -1 or 1
 
 type Game struct {
-	Repo      string
+	Repo      string // what high scores are kept under, along with the difficulty
+	Repos     []string
 	debug     bool
 	drawables []Drawable
 	Screen    tcell.Screen
@@ -77,6 +81,52 @@ type Game struct {
 	MaxWidth  int
 	Logger    *log.Logger
 	State     *stateEntry
+	Config    *configEntry
+	Triaged   []issueEntry
+
+	// Events delivers the player's input.
+	Events <-chan tcell.Event
+	// Clock paces the game, delivering once per frame. It defaults to a
+	// frame every Difficulty.FrameDelay.
+	Clock func() <-chan time.Time
+	// Seed drives every random choice the game makes.
+	Seed int64
+	// Difficulty sets the pace; it defaults to normal.
+	Difficulty     difficulty
+	DifficultyName string
+
+	rng      *rand.Rand
+	spawners []*IssueSpawner
+	launcher *CommitLauncher
+	score    *Score
+	health   *BacklogHealth
+	dealt    int     // issues handed to spawners so far
+	feeding  bool    // set while more issues are on their way
+	ticks    int64   // frames played so far
+	owed     float64 // fraction of a point scored but not yet counted
+
+	recording *replay
+}
+
+const frameDelay = 100 * time.Millisecond
+
+var repoColors = []tcell.Color{
+	tcell.ColorWhite,
+	tcell.ColorAqua,
+	tcell.ColorFuchsia,
+	tcell.ColorOrange,
+	tcell.ColorLightSkyBlue,
+	tcell.ColorSilver,
+}
+
+// RepoColor picks a color to tell issues from different repositories apart.
+func (g *Game) RepoColor(repo string) tcell.Color {
+	for ix, r := range g.Repos {
+		if r == repo {
+			return repoColors[ix%len(repoColors)]
+		}
+	}
+	return tcell.ColorWhite
 }
 
 func (g *Game) Debugf(format string, v ...interface{}) {
@@ -194,6 +244,222 @@ func (g *Game) Draw() {
 	}
 }
 
+// Setup lays out the board: the issue spawners down either side, the
+// launcher loaded with shas and the score keeping below it.
+func (g *Game) Setup(shas []string) {
+	g.rng = rand.New(rand.NewSource(g.Seed))
+	if g.Difficulty == (difficulty{}) {
+		g.Difficulty = difficultyPresets[defaultDifficulty]
+	}
+
+	y := 2
+	x := 0
+	for i := 0; i < 10; i++ {
+		if i%2 == 0 {
+			x = 0
+		} else {
+			x = g.MaxWidth
+		}
+
+		is := NewIssueSpawner(x, y+i, g)
+
+		g.spawners = append(g.spawners, is)
+		g.AddDrawable(is)
+	}
+
+	g.launcher = NewCommitLauncher(g, shas)
+	g.launcher.Transform(37, 13)
+	g.AddDrawable(g.launcher)
+
+	g.AddDrawable(NewCommitCounter(35, 14, g.launcher, g))
+
+	g.score = NewScore(38, 18, g)
+	g.AddDrawable(g.score)
+
+	g.health = NewBacklogHealth(38, 19, g.Difficulty.BacklogHealth, g)
+	g.AddDrawable(g.health)
+
+	g.AddDrawable(NewScoreLog(15, 15, g))
+
+	g.AddDrawable(NewLegend(1, 15, g))
+
+	g.AddDrawable(NewHighScores(60, 15, g))
+}
+
+// AddIssues deals issues out to the spawners in turn.
+func (g *Game) AddIssues(issues []issueEntry) {
+	for _, issue := range issues {
+		g.spawners[g.dealt%len(g.spawners)].AddIssue(issue)
+		g.dealt++
+	}
+}
+
+// HighScoreKey is what this game's high scores are saved under.
+func (g *Game) HighScoreKey() string {
+	return difficultyKey(g.Repo, g.DifficultyName)
+}
+
+// Score is the player's score so far.
+func (g *Game) Score() int {
+	return g.score.score
+}
+
+// HandleEvent applies a single input event, reporting whether the player
+// asked to quit.
+func (g *Game) HandleEvent(ev tcell.Event) (quit bool) {
+	g.recordEvent(ev)
+
+	cl := g.launcher
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Rune() {
+		case ' ':
+			cl.Launch()
+		case 'q':
+			return true
+		}
+		switch ev.Key() {
+		case tcell.KeyEscape:
+			return true
+		case tcell.KeyCtrlL:
+			g.Screen.Sync()
+		case tcell.KeyLeft:
+			if cl.x > 0 {
+				cl.Transform(-1, 0)
+			}
+		case tcell.KeyRight:
+			if cl.w+cl.x < g.MaxWidth {
+				cl.Transform(1, 0)
+
+			}
+		}
+	case *tcell.EventResize:
+		g.Screen.Sync()
+	}
+	return false
+}
+
+// Overwhelmed reports whether escaped issues have used up the backlog's
+// health.
+func (g *Game) Overwhelmed() bool {
+	return g.health.Empty()
+}
+
+// Over reports whether the game has run its course: the backlog is
+// overwhelmed, the launcher is out of commits or every issue has been spawned
+// and is gone.
+func (g *Game) Over() bool {
+	if g.Overwhelmed() || len(g.launcher.Shas) == 0 {
+		return true
+	}
+	if g.feeding {
+		return false
+	}
+
+	for _, s := range g.spawners {
+		if len(s.issues) > 0 {
+			return false
+		}
+	}
+
+	issuesRemain := false
+	_ = g.FilterGameObjects(func(gobj Drawable) bool {
+		_, ok := gobj.(*Issue)
+		if ok {
+			issuesRemain = true
+			return false
+		}
+		return true
+	})
+
+	return !issuesRemain
+}
+
+// Tick advances the game by a frame and redraws the screen.
+func (g *Game) Tick() {
+	s := g.Screen
+	s.Clear()
+	spawner := g.spawners[g.rng.Intn(len(g.spawners))]
+	spawner.Spawn()
+	g.Update()
+	g.Draw()
+	titleStyle := g.Style.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
+	title := "!!! M E R G E  C O N F L I C T !!!"
+	drawStr(s, 25, 0, titleStyle, title)
+	drawStr(s, 25+len(title)+3, 0, g.Style, fmt.Sprintf("np: %s", g.HighScoreKey()))
+	s.Show()
+	g.ticks++
+}
+
+// Run plays until the player quits or the game is over. Issues keep arriving
+// on feed while we play until it's closed; it may be nil.
+//
+// Input is queued as it arrives and only applied at the start of the next
+// frame, so everything that changes the game happens on this goroutine and
+// frames come at a fixed rate however fast the player types.
+func (g *Game) Run(feed <-chan []issueEntry) {
+	g.feeding = feed != nil
+	clock, stop := g.clock()
+	defer stop()
+
+	queued := []tcell.Event{}
+	for {
+		feed = g.takeIssues(feed)
+		select {
+		case ev := <-g.Events:
+			queued = append(queued, ev)
+			continue
+		case <-clock():
+		}
+
+		for _, ev := range queued {
+			if g.HandleEvent(ev) {
+				return
+			}
+		}
+		queued = queued[:0]
+		if g.Over() {
+			return
+		}
+		g.Tick()
+	}
+}
+
+// clock paces frames at a fixed rate unless the game was given its own Clock.
+func (g *Game) clock() (next func() <-chan time.Time, stop func()) {
+	if g.Clock != nil {
+		return g.Clock, func() {}
+	}
+	ticker := time.NewTicker(g.Difficulty.FrameDelay)
+	return func() <-chan time.Time { return ticker.C }, ticker.Stop
+}
+
+// takeIssues hands any issues waiting on feed to the spawners, returning nil
+// once feed is closed.
+func (g *Game) takeIssues(feed <-chan []issueEntry) <-chan []issueEntry {
+	for {
+		select {
+		case page, ok := <-feed:
+			if !ok {
+				g.feeding = false
+				return nil
+			}
+			g.deal(page)
+		default:
+			return feed
+		}
+	}
+}
+
+// deal shuffles a batch of newly arrived issues into the spawners.
+func (g *Game) deal(issues []issueEntry) {
+	g.recordBatch(issues)
+	g.rng.Shuffle(len(issues), func(i, j int) {
+		issues[i], issues[j] = issues[j], issues[i]
+	})
+	g.AddIssues(issues)
+}
+
 func (g *Game) FindGameObject(fn func(Drawable) bool) Drawable {
 	for _, gobj := range g.drawables {
 		if fn(gobj) {
@@ -213,7 +479,7 @@ func (g *Game) FilterGameObjects(fn func(Drawable) bool) []Drawable {
 	return out
 }
 
-func (g *Game) DetectHits(r *Ray, shot *CommitShot) {
+func (g *Game) AddScore(points int, bonus bool) {
 	score := g.FindGameObject(func(gobj Drawable) bool {
 		_, ok := gobj.(*Score)
 		return ok
@@ -228,7 +494,13 @@ func (g *Game) DetectHits(r *Ray, shot *CommitShot) {
 	if scoreLog == nil {
 		panic("could not find score log game object")
 	}
+	scoreLog.(*ScoreLog).Log(points, bonus)
+	score.(*Score).Add(points)
+}
+
+func (g *Game) DetectHits(r *Ray, shot *CommitShot) {
 	thisShot := 0
+	points := 0.0
 	matchesMultiplier := 1
 
 	// TODO dirty to do side effects in a filter, consider renaming/tweaking
@@ -248,9 +520,18 @@ func (g *Game) DetectHits(r *Ray, shot *CommitShot) {
 			return false
 		}
 
+		if issue.Indestructible() {
+			g.AddDrawable(NewBurst(shotX, issue.y, g))
+			return false
+		}
+
 		thisShot++
+		points += issue.Value()
 
 		issue.DestroyLetterAt(shotX - issue.x)
+		if issue.Cleared() {
+			g.Triaged = append(g.Triaged, issue.Entry)
+		}
 
 		var burst *Burst
 
@@ -272,12 +553,18 @@ func (g *Game) DetectHits(r *Ray, shot *CommitShot) {
 	}
 	if matchesMultiplier > 1 {
 		bonus = true
-		thisShot *= matchesMultiplier
+		points *= float64(matchesMultiplier)
 	}
 
-	if thisShot > 0 {
-		scoreLog.(*ScoreLog).Log(thisShot, bonus)
-		score.(*Score).Add(thisShot)
+	if thisShot == 0 {
+		return
+	}
+	// half-point letters add up over several shots instead of rounding up
+	points += g.owed
+	whole := math.Floor(points)
+	g.owed = points - whole
+	if whole > 0 {
+		g.AddScore(int(whole), bonus)
 	}
 }
 
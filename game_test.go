@@ -0,0 +1,339 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// testGameOpts changes how newTestGameWith sets up a game. The zero value
+// plays on normal difficulty with seed 0.
+type testGameOpts struct {
+	Seed       int64
+	Difficulty difficulty
+}
+
+// newTestGame sets up a game on a simulated screen the size of a small
+// terminal.
+func newTestGame(t *testing.T, shas []string) (*Game, tcell.SimulationScreen) {
+	return newTestGameWith(t, testGameOpts{}, shas)
+}
+
+func newTestGameWith(t *testing.T, opts testGameOpts, shas []string) (*Game, tcell.SimulationScreen) {
+	t.Helper()
+
+	s := tcell.NewSimulationScreen("")
+	if err := s.Init(); err != nil {
+		t.Fatalf("failed to init screen: %s", err)
+	}
+	t.Cleanup(s.Fini)
+	s.SetSize(minWidth, minHeight)
+
+	game := &Game{
+		Repo:       "cli/cli",
+		Screen:     s,
+		Style:      tcell.StyleDefault,
+		MaxWidth:   minWidth,
+		State:      &stateEntry{HighScores: map[string][]scoreEntry{}},
+		Config:     &configEntry{},
+		Seed:       opts.Seed,
+		Difficulty: opts.Difficulty,
+	}
+	game.Setup(shas)
+
+	return game, s
+}
+
+// row is the text rendered on line y of the screen.
+func row(s tcell.SimulationScreen, y int) string {
+	cells, w, _ := s.GetContents()
+	out := []rune{}
+	for x := 0; x < w; x++ {
+		c := cells[y*w+x]
+		if len(c.Runes) == 0 {
+			out = append(out, ' ')
+			continue
+		}
+		out = append(out, c.Runes[0])
+	}
+	return string(out)
+}
+
+func keyEvent(key tcell.Key) tcell.Event {
+	return tcell.NewEventKey(key, 0, tcell.ModNone)
+}
+
+func runeEvent(r rune) tcell.Event {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestLauncherMoves(t *testing.T) {
+	game, s := newTestGame(t, []string{strings.Repeat("0", 40)})
+
+	game.Tick()
+	if got := strings.Index(row(s, 13), "-=$^$=-"); got != 37 {
+		t.Fatalf("launcher drawn at %d, want 37", got)
+	}
+
+	game.HandleEvent(keyEvent(tcell.KeyLeft))
+	game.HandleEvent(keyEvent(tcell.KeyLeft))
+	game.HandleEvent(keyEvent(tcell.KeyRight))
+	game.Tick()
+	if got := strings.Index(row(s, 13), "-=$^$=-"); got != 36 {
+		t.Errorf("launcher drawn at %d, want 36", got)
+	}
+}
+
+func TestShootingAnIssue(t *testing.T) {
+	shas := []string{
+		strings.Repeat("0", 40),
+		strings.Repeat("1", 40),
+	}
+	game, s := newTestGame(t, shas)
+	game.AddIssues([]issueEntry{{Number: 1, Title: "zzzzzzzzzz"}})
+
+	// wait for the issue to drift under the launcher's barrel
+	shotX := game.launcher.x + 3
+	var issue *Issue
+	for i := 0; i < 500; i++ {
+		game.Tick()
+		found := game.FindGameObject(func(d Drawable) bool {
+			_, ok := d.(*Issue)
+			return ok
+		})
+		if found == nil {
+			continue
+		}
+		issue = found.(*Issue)
+		if issue.x <= shotX && shotX < issue.x+issue.w && issue.LetterAt(shotX-issue.x) == 'z' {
+			break
+		}
+	}
+	if issue == nil {
+		t.Fatal("issue never spawned")
+	}
+
+	if quit := game.HandleEvent(runeEvent(' ')); quit {
+		t.Fatal("firing quit the game")
+	}
+	if got := issue.LetterAt(shotX - issue.x); got != ' ' {
+		t.Errorf("letter under the shot is %q, want it shot away", got)
+	}
+	if game.Score() != 1 {
+		t.Errorf("got score %d, want 1", game.Score())
+	}
+
+	game.Tick()
+	if !strings.Contains(row(s, 18), "SCORE: 1") {
+		t.Errorf("score line reads %q", strings.TrimSpace(row(s, 18)))
+	}
+	if !strings.Contains(row(s, 15), "1 points!") {
+		t.Errorf("score log reads %q", strings.TrimSpace(row(s, 15)))
+	}
+	if !strings.Contains(row(s, 14), "1 commits remain") {
+		t.Errorf("commit counter reads %q", strings.TrimSpace(row(s, 14)))
+	}
+}
+
+func TestGameOverWhenOutOfCommits(t *testing.T) {
+	game, _ := newTestGame(t, []string{strings.Repeat("0", 40)})
+	game.AddIssues([]issueEntry{{Number: 1, Title: "still open"}})
+
+	if game.Over() {
+		t.Fatal("game over before it started")
+	}
+	game.HandleEvent(runeEvent(' '))
+	if !game.Over() {
+		t.Error("game not over after firing the last commit")
+	}
+}
+
+func TestRun(t *testing.T) {
+	game, s := newTestGame(t, []string{strings.Repeat("0", 40)})
+
+	events := make(chan tcell.Event)
+	ticks := make(chan time.Time)
+	game.Events = events
+	game.Clock = func() <-chan time.Time {
+		return ticks
+	}
+
+	feed := make(chan []issueEntry, 1)
+	feed <- []issueEntry{{Number: 1, Title: "hello"}}
+	close(feed)
+
+	done := make(chan struct{})
+	go func() {
+		game.Run(feed)
+		close(done)
+	}()
+
+	ticks <- time.Now()
+	events <- keyEvent(tcell.KeyLeft)
+	events <- keyEvent(tcell.KeyLeft)
+	ticks <- time.Now()
+	events <- runeEvent('q')
+
+	// input only takes effect on the next frame
+	select {
+	case <-done:
+		t.Fatal("game quit before the next frame")
+	case <-time.After(10 * time.Millisecond):
+	}
+	ticks <- time.Now()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("game did not quit")
+	}
+
+	if game.ticks != 2 {
+		t.Errorf("played %d frames, want 2", game.ticks)
+	}
+	if game.launcher.x != 35 {
+		t.Errorf("launcher at %d, want 35", game.launcher.x)
+	}
+
+	if !strings.Contains(row(s, 0), "np: cli/cli") {
+		t.Errorf("title reads %q", strings.TrimSpace(row(s, 0)))
+	}
+	if game.dealt != 1 {
+		t.Errorf("dealt %d issues, want 1", game.dealt)
+	}
+}
+
+func TestSeededGamesMatch(t *testing.T) {
+	play := func(seed int64) string {
+		game, s := newTestGameWith(t, testGameOpts{Seed: seed}, []string{strings.Repeat("0", 40)})
+
+		feed := make(chan []issueEntry, 1)
+		feed <- makeIssues(30)
+		close(feed)
+		game.takeIssues(feed)
+
+		for i := 0; i < 100; i++ {
+			game.Tick()
+		}
+		rows := []string{}
+		for y := 2; y < 12; y++ {
+			rows = append(rows, row(s, y))
+		}
+		return strings.Join(rows, "\n")
+	}
+
+	board := play(42)
+	if strings.TrimSpace(board) == "" {
+		t.Fatal("no issues on the board")
+	}
+	if again := play(42); again != board {
+		t.Errorf("same seed gave different boards:\n%s\n\n%s", board, again)
+	}
+	if other := play(7); other == board {
+		t.Error("different seeds gave the same board")
+	}
+}
+
+// playUntilOver ticks until the game is over.
+func playUntilOver(t *testing.T, game *Game) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if game.Over() {
+			return
+		}
+		game.Tick()
+	}
+	t.Fatal("game never ended")
+}
+
+func TestEscapedIssuesDrainBacklog(t *testing.T) {
+	game, s := newTestGame(t, []string{strings.Repeat("0", 40)})
+	max := game.health.max
+	game.AddIssues([]issueEntry{
+		{Number: 1, Title: "hello there"},
+		{Number: 2, Title: "never mind", Labels: []string{"wontfix"}},
+	})
+
+	playUntilOver(t, game)
+
+	// "#1 hello there" has 12 letters; the wontfix issue costs nothing
+	if got := game.health.health; got != max-12 {
+		t.Errorf("got health %d, want %d", got, max-12)
+	}
+	if game.Overwhelmed() {
+		t.Error("overwhelmed by a single issue")
+	}
+	if !strings.Contains(row(s, 19), "BACKLOG [##########]") {
+		t.Errorf("backlog meter reads %q", strings.TrimSpace(row(s, 19)))
+	}
+}
+
+func TestOverwhelmedBacklogEndsGame(t *testing.T) {
+	d := difficultyPresets["normal"]
+	d.BacklogHealth = 10
+	game, s := newTestGameWith(t, testGameOpts{Difficulty: d}, []string{strings.Repeat("0", 40)})
+	game.AddIssues([]issueEntry{
+		{Number: 1, Title: "hello there"},
+		{Number: 2, Title: "still here"},
+	})
+
+	playUntilOver(t, game)
+	if !game.Overwhelmed() {
+		t.Error("game ended without the backlog running out")
+	}
+	if !strings.Contains(row(s, 19), "BACKLOG [----------]") {
+		t.Errorf("backlog meter reads %q", strings.TrimSpace(row(s, 19)))
+	}
+}
+
+func TestSlowIssuesClearTheSpawner(t *testing.T) {
+	game, _ := newTestGame(t, []string{strings.Repeat("0", 40)})
+	spawner := game.spawners[0]
+	spawner.AddIssue(issueEntry{Number: 1, Title: "easy", Labels: []string{"good first issue"}})
+
+	spawner.Spawn()
+	// "#1 easy" moves half a cell a frame, so it takes 14 frames to clear
+	want := 14 + game.Difficulty.SpawnCooldown
+	if spawner.countdown != want {
+		t.Errorf("got countdown %d, want %d", spawner.countdown, want)
+	}
+}
+
+func TestEasyIssuesClearTheSpawner(t *testing.T) {
+	game, _ := newTestGameWith(t, testGameOpts{Difficulty: difficultyPresets["easy"]}, []string{strings.Repeat("0", 40)})
+	spawner := game.spawners[0]
+	spawner.AddIssue(issueEntry{Number: 1, Title: "easy"})
+
+	spawner.Spawn()
+	// "#1 easy" moves three quarters of a cell a frame, so it takes 10
+	// frames to clear
+	want := 10 + game.Difficulty.SpawnCooldown
+	if spawner.countdown != want {
+		t.Errorf("got countdown %d, want %d", spawner.countdown, want)
+	}
+}
+
+func TestHalfPointHitsAddUp(t *testing.T) {
+	game, _ := newTestGame(t, []string{strings.Repeat("0", 40)})
+	issue := NewIssue(10, 2, 1, issueEntry{Number: 1, Title: "zzzz", Labels: []string{"good first issue"}}, game)
+	game.AddDrawable(issue)
+
+	shoot := func(x int) {
+		ray := &Ray{}
+		for y := 12; y >= issue.y; y-- {
+			ray.AddPoint(x, y)
+		}
+		game.DetectHits(ray, NewCommitShot(game, x, 12, strings.Repeat("0", 40)))
+	}
+
+	shoot(13)
+	if game.Score() != 0 {
+		t.Errorf("got score %d after one half-point hit, want 0", game.Score())
+	}
+	shoot(14)
+	if game.Score() != 1 {
+		t.Errorf("got score %d after two half-point hits, want 1", game.Score())
+	}
+}
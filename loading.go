@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// loadProgress counts pages fetched while loading, along with anything the
+// player should know about how loading is going. It is safe to use from
+// several goroutines.
+type loadProgress struct {
+	pages int64
+
+	mu    sync.Mutex
+	notes []string
+}
+
+func (lp *loadProgress) Page() {
+	atomic.AddInt64(&lp.pages, 1)
+}
+
+func (lp *loadProgress) Pages() int {
+	return int(atomic.LoadInt64(&lp.pages))
+}
+
+// Note adds a line to show on the loading screen.
+func (lp *loadProgress) Note(format string, a ...interface{}) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	lp.notes = append(lp.notes, fmt.Sprintf(format, a...))
+}
+
+func (lp *loadProgress) Notes() []string {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	return append([]string{}, lp.notes...)
+}
+
+// gameFeed delivers game data as it's fetched so play can start before
+// every page of issues has arrived.
+type gameFeed struct {
+	// Issues receives issues and pull requests a page at a time. It is
+	// closed once every page has arrived.
+	Issues <-chan []issueEntry
+	// Ready delivers once every commit and at least one page of issues have
+	// been fetched, or fetching failed.
+	Ready <-chan error
+	// Repos and SHAs may only be read once Ready has delivered nil.
+	Repos []string
+	SHAs  []string
+
+	stopped chan struct{}
+	stop    sync.Once
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// Stop tells the feed nobody is reading Issues any more. Fetching carries on
+// so the cache still gets everything, but pages are dropped rather than
+// waiting forever to be read.
+func (gf *gameFeed) Stop() {
+	gf.stop.Do(func() { close(gf.stopped) })
+}
+
+// Done is closed once every page of issues has been fetched or failed.
+func (gf *gameFeed) Done() <-chan struct{} {
+	return gf.done
+}
+
+func (gf *gameFeed) fail(err error) {
+	gf.mu.Lock()
+	defer gf.mu.Unlock()
+	if gf.err == nil {
+		gf.err = err
+	}
+}
+
+// Err is the first error hit fetching issues, if any.
+func (gf *gameFeed) Err() error {
+	gf.mu.Lock()
+	defer gf.mu.Unlock()
+	return gf.err
+}
+
+// loadGameData starts fetching the issues (and pull requests, if asked for)
+// and commits of every repository concurrently, once opts.Prepare is done.
+func loadGameData(opts mcOpts) *gameFeed {
+	issues := make(chan []issueEntry, 16)
+	ready := make(chan error, 1)
+	issuesDone := make(chan struct{})
+	feed := &gameFeed{
+		Issues:  issues,
+		Ready:   ready,
+		stopped: make(chan struct{}),
+		done:    issuesDone,
+	}
+
+	go func() {
+		if opts.Prepare != nil {
+			if err := opts.Prepare(&opts); err != nil {
+				close(issues)
+				close(issuesDone)
+				ready <- err
+				return
+			}
+		}
+		feed.Repos = opts.Repositories
+		feed.fetch(opts, issues, ready, issuesDone)
+	}()
+
+	return feed
+}
+
+// fetch does the fetching for loadGameData once it knows which repositories
+// to fetch from.
+func (gf *gameFeed) fetch(opts mcOpts, issues chan<- []issueEntry, ready chan<- error, issuesDone chan struct{}) {
+	multiRepo := len(opts.Repositories) > 1
+
+	gotPage := make(chan struct{})
+	var firstPage sync.Once
+	// a seeded game needs every issue before it starts, in an order that
+	// doesn't depend on which request came back first
+	var collected []issueEntry
+	var collectedMu sync.Mutex
+	var issuesWG sync.WaitGroup
+	for _, repo := range opts.Repositories {
+		repo := repo
+		send := func(page []issueEntry) {
+			if len(page) == 0 {
+				return
+			}
+			if multiRepo {
+				for i := range page {
+					page[i].Repo = repo
+				}
+			}
+			if opts.Seeded {
+				collectedMu.Lock()
+				collected = append(collected, page...)
+				collectedMu.Unlock()
+				return
+			}
+			select {
+			case issues <- page:
+				firstPage.Do(func() { close(gotPage) })
+			case <-gf.stopped:
+			}
+		}
+
+		issuesWG.Add(1)
+		go func() {
+			defer issuesWG.Done()
+			err := streamIssues(opts.Source, repo, send)
+			if err != nil {
+				gf.fail(fmt.Errorf("failed to get issues for %s: %w", repo, err))
+			}
+		}()
+
+		if opts.PullRequests {
+			issuesWG.Add(1)
+			go func() {
+				defer issuesWG.Done()
+				err := streamPullRequests(opts.Source, repo, send)
+				if err != nil {
+					gf.fail(fmt.Errorf("failed to get pull requests for %s: %w", repo, err))
+				}
+			}()
+		}
+	}
+	go func() {
+		issuesWG.Wait()
+		if len(collected) > 0 {
+			sortIssues(collected)
+			select {
+			case issues <- collected:
+				close(gotPage)
+			case <-gf.stopped:
+			}
+		}
+		close(issues)
+		close(issuesDone)
+	}()
+
+	shaLists := make([][]string, len(opts.Repositories))
+	var shaWG sync.WaitGroup
+	var shaMu sync.Mutex
+	var shaErr error
+	for ix, repo := range opts.Repositories {
+		ix, repo := ix, repo
+		shaWG.Add(1)
+		go func() {
+			defer shaWG.Done()
+			shas, err := opts.Source.SHAs(repo)
+			if err != nil {
+				shaMu.Lock()
+				if shaErr == nil {
+					shaErr = fmt.Errorf("failed to get shas for %s: %w", repo, err)
+				}
+				shaMu.Unlock()
+				return
+			}
+			shaLists[ix] = shas
+		}()
+	}
+
+	go func() {
+		shaWG.Wait()
+		if shaErr != nil {
+			ready <- shaErr
+			return
+		}
+		gf.SHAs = interleave(shaLists)
+
+		select {
+		case <-gotPage:
+			ready <- nil
+		case <-issuesDone:
+			select {
+			case <-gotPage:
+				ready <- nil
+			default:
+				// not a single issue; that's only fine if nothing went wrong
+				ready <- gf.Err()
+			}
+		}
+	}()
+}
+
+// sortIssues puts issues in a stable order: by repository, issues before
+// pull requests, then by number.
+func sortIssues(issues []issueEntry) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		if a.PullRequest != b.PullRequest {
+			return !a.PullRequest
+		}
+		return a.Number < b.Number
+	})
+}
+
+var spinner = []string{"|", "/", "-", "\\"}
+
+// showLoading animates a loading screen until ready delivers, returning its
+// error, or until the player gives up by pressing escape.
+func showLoading(s tcell.Screen, style tcell.Style, label string, progress *loadProgress, events <-chan tcell.Event, ready <-chan error) (cancelled bool, err error) {
+	titleStyle := style.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
+	title := "!!! M E R G E  C O N F L I C T !!!"
+	frame := 0
+	for {
+		s.Clear()
+		drawStr(s, 25, 0, titleStyle, title)
+		drawStr(s, 25, 8, style, fmt.Sprintf("%s loading %s", spinner[frame%len(spinner)], label))
+		drawStr(s, 27, 9, style, fmt.Sprintf("%d pages fetched", progress.Pages()))
+		drawStr(s, 27, 11, style.Foreground(tcell.ColorGray), "esc: cancel")
+		for i, note := range progress.Notes() {
+			drawStr(s, 27, 13+i, style.Foreground(tcell.ColorYellow), note)
+		}
+		s.Show()
+		frame++
+
+		select {
+		case err := <-ready:
+			return false, err
+		case ev := <-events:
+			switch ev := ev.(type) {
+			case *tcell.EventKey:
+				if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
+					return true, nil
+				}
+			case *tcell.EventResize:
+				s.Sync()
+			}
+		case <-time.After(time.Millisecond * 100):
+		}
+	}
+}
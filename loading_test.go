@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFeedWaitsForPrepare(t *testing.T) {
+	repos := []string{"cli/cli"}
+	feed := loadGameData(mcOpts{
+		Prepare: func(opts *mcOpts) error {
+			opts.Repositories = repos
+			opts.Source = fileSource{fixture: &fixture{
+				Issues:  makeIssues(2),
+				Commits: makeSHAs(3),
+			}}
+			return nil
+		},
+	})
+	if err := <-feed.Ready; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(feed.Repos, repos) {
+		t.Errorf("got repos %v, want %v", feed.Repos, repos)
+	}
+	if len(feed.SHAs) != 3 {
+		t.Errorf("got %d shas, want 3", len(feed.SHAs))
+	}
+
+	failed := loadGameData(mcOpts{
+		Prepare: func(opts *mcOpts) error {
+			return errors.New("no such organization")
+		},
+	})
+	if err := <-failed.Ready; err == nil {
+		t.Error("expected preparing to fail")
+	}
+	if _, ok := <-failed.Issues; ok {
+		t.Error("got issues after preparing failed")
+	}
+}
+
+func TestStoppedFeedStillCaches(t *testing.T) {
+	useTempState(t)
+	f := newFakeGitHub(t, "cli", "cli")
+	f.PageSize = 10
+	f.Issues = makeIssues(300)
+	f.Commits = makeSHAs(5)
+
+	feed := loadGameData(mcOpts{
+		Repositories: []string{f.Repo()},
+		Source:       newCachedSource(0),
+	})
+	select {
+	case err := <-feed.Ready:
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("feed never got ready")
+	}
+
+	// the game ends after a single page, leaving the rest unread
+	<-feed.Issues
+	feed.Stop()
+	select {
+	case <-feed.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetching got stuck once nobody was reading")
+	}
+
+	_, cached, usable, err := newCachedSource(0).lookup(f.Repo(), "issues", "")
+	if err != nil || !usable {
+		t.Fatalf("nothing usable cached: %v", err)
+	}
+	if len(cached.Issues) != 300 {
+		t.Errorf("cached %d issues, want all 300", len(cached.Issues))
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"math/rand"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -19,8 +21,153 @@ const (
 )
 
 type mcOpts struct {
-	Repository string
-	Debug      bool
+	Repositories []string
+	Org          string
+	Hostname     string
+	Debug        bool
+	Local        bool
+	DataFile     string
+	PullRequests bool
+	Filter       issueFilter
+	Commits      commitRange
+	MaxPages     int
+	CacheTTL     time.Duration
+	Refresh      bool
+	Offline      bool
+	Seed         int64 // drives every random choice in the game
+	Seeded       bool  // fetch every issue up front so the board comes out the same
+	Record       string
+	Difficulty   string
+	Source       DataSource
+	Progress     *loadProgress
+	// Prepare, if set, finishes setting up once the loading screen is up,
+	// for anything slow enough to need one.
+	Prepare func(opts *mcOpts) error
+}
+
+// setupGitHub works out which repositories to play in. Anything that takes
+// API requests is left to prepareGitHub, behind the loading screen.
+func setupGitHub(opts *mcOpts) error {
+	opts.Hostname = hostname(opts.Hostname)
+
+	if opts.Org != "" && opts.Offline {
+		return errors.New("--org can't be used with --offline")
+	}
+
+	// picking a remote may mean asking the player, which can't wait until
+	// the loading screen has the terminal
+	if opts.Org == "" {
+		if len(opts.Repositories) == 0 {
+			repo, err := resolveRepository(opts.Hostname)
+			if err != nil {
+				return err
+			}
+			opts.Repositories = []string{repo}
+		}
+
+		repos, err := normalizeRepos(opts.Repositories, opts.Hostname)
+		if err != nil {
+			return err
+		}
+		opts.Repositories = repos
+	}
+
+	opts.Progress = &loadProgress{}
+	opts.Prepare = prepareGitHub
+
+	return nil
+}
+
+// prepareGitHub lists the repositories of the organization being played, if
+// any, and works out how much of the API quota fetching them may use.
+func prepareGitHub(opts *mcOpts) error {
+	if opts.Org != "" {
+		opts.Progress.Note("listing the repositories in %s", opts.Org)
+		orgRepos, err := getOrgRepos(opts.Hostname, opts.Org, pager{
+			MaxPages: opts.MaxPages,
+			OnPage:   opts.Progress.Page,
+		})
+		if err != nil {
+			return err
+		}
+		if len(orgRepos) == 0 {
+			return fmt.Errorf("no repositories with issues enabled found in %s", opts.Org)
+		}
+		repos, err := normalizeRepos(orgRepos, opts.Hostname)
+		if err != nil {
+			return err
+		}
+		opts.Repositories = repos
+	}
+	repos := opts.Repositories
+
+	// issues and commits for every repository, plus pull requests if asked for
+	queries := 2 * len(repos)
+	if opts.PullRequests {
+		queries += len(repos)
+	}
+	var exhausted map[string]bool
+	if !opts.Offline {
+		opts.MaxPages, exhausted = limitPages(repos, queries, opts.MaxPages, opts.Progress)
+	}
+
+	opts.Source = cachedSource{
+		Source: ghSource{
+			Filter:  opts.Filter,
+			Commits: opts.Commits,
+			Pager: pager{
+				MaxPages: opts.MaxPages,
+				OnPage:   opts.Progress.Page,
+			},
+		},
+		TTL:         opts.CacheTTL,
+		Refresh:     opts.Refresh,
+		Offline:     opts.Offline,
+		Exhausted:   exhausted,
+		IssueQuery:  fmt.Sprintf("%+v", opts.Filter),
+		CommitQuery: fmt.Sprintf("%+v", opts.Commits),
+		MaxPages:    opts.MaxPages,
+	}
+
+	return nil
+}
+
+// limitPages lowers maxPages if any host the repos live on is running low on
+// API quota, noting it on the loading screen. Hosts with no quota left at all
+// are returned so their repositories can be played from the cache instead.
+func limitPages(repos []string, queries int, maxPages int, progress *loadProgress) (int, map[string]bool) {
+	checked := map[string]bool{}
+	exhausted := map[string]bool{}
+	for _, repo := range repos {
+		ref, err := parseRepo(repo, defaultHost)
+		if err != nil || checked[ref.Host] {
+			continue
+		}
+		checked[ref.Host] = true
+
+		limit, err := getRateLimit(ref.Host)
+		if err != nil {
+			// not every GitHub Enterprise instance has rate limiting turned on
+			continue
+		}
+		if limit.Remaining == 0 {
+			exhausted[ref.Host] = true
+			progress.Note("out of API quota for %s until %s,", ref.Host, limit.ResetAt().Format(time.Kitchen))
+			progress.Note("so playing with what was cached from earlier games")
+			continue
+		}
+		budget := pageBudget(limit, queries)
+		if budget == 0 {
+			continue
+		}
+		if maxPages == 0 || budget < maxPages {
+			maxPages = budget
+		}
+		progress.Note("running low on API quota for %s:", ref.Host)
+		progress.Note("%d of %d requests left until %s,", limit.Remaining, limit.Limit, limit.ResetAt().Format(time.Kitchen))
+		progress.Note("so fetching at most %d pages per query", maxPages)
+	}
+	return maxPages, exhausted
 }
 
 func rootCmd() *cobra.Command {
@@ -30,223 +177,453 @@ func rootCmd() *cobra.Command {
 		Short:         "play a game about open source triage in your terminal",
 		Args:          cobra.ExactArgs(0),
 		SilenceErrors: true,
+		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if opts.Repository == "" {
-				repo, err := resolveRepository()
+			offline := opts.Local || opts.DataFile != ""
+			if opts.Local && opts.DataFile != "" {
+				return errors.New("specify only one of --local or --data")
+			}
+			if len(opts.Repositories) > 0 && opts.Org != "" {
+				return errors.New("specify only one of --repo or --org")
+			}
+			if offline && !opts.Filter.IsEmpty() {
+				return errors.New("--label, --assignee, --milestone and --search only apply when playing against GitHub")
+			}
+			if opts.DataFile != "" && !opts.Commits.IsEmpty() {
+				return errors.New("--ref, --since, --until and --max-commits can't be used with --data")
+			}
+			if offline && (len(opts.Repositories) > 1 || opts.Org != "") {
+				return errors.New("multiple repositories and --org only apply when playing against GitHub")
+			}
+			if offline && (opts.Hostname != "" || opts.MaxPages != 0 || opts.Refresh || opts.Offline) {
+				return errors.New("--hostname, --max-pages, --refresh and --offline only apply when playing against GitHub")
+			}
+			if opts.Refresh && opts.Offline {
+				return errors.New("specify only one of --refresh or --offline")
+			}
+			if opts.Difficulty != "" {
+				if err := validDifficulty(opts.Difficulty); err != nil {
+					return err
+				}
+			}
+
+			opts.Seeded = cmd.Flags().Changed("seed")
+			if !opts.Seeded {
+				opts.Seed = time.Now().UnixNano()
+			}
+
+			switch {
+			case opts.DataFile != "":
+				f, err := loadFixture(opts.DataFile)
+				if err != nil {
+					return err
+				}
+				opts.Source = fileSource{fixture: f}
+				if len(opts.Repositories) == 0 {
+					repo := f.Repository
+					if repo == "" {
+						repo = filepath.Base(opts.DataFile)
+					}
+					opts.Repositories = []string{repo}
+				}
+			case opts.Local:
+				opts.Source = gitSource{Commits: opts.Commits}
+				if len(opts.Repositories) == 0 {
+					repo, err := resolveLocalRepository()
+					if err != nil {
+						return err
+					}
+					opts.Repositories = []string{repo}
+				}
+			default:
+				err := setupGitHub(&opts)
 				if err != nil {
 					return err
 				}
-				opts.Repository = repo
 			}
+
 			return runMC(opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.Repository, "repo", "R", "", "Repository to play in")
+	cmd.AddCommand(exportCmd())
+	cmd.AddCommand(cacheCmd())
+	cmd.AddCommand(replayCmd())
+
+	cmd.Flags().StringSliceVarP(&opts.Repositories, "repo", "R", nil, "Repository to play in (repeatable)")
+	cmd.Flags().StringVar(&opts.Org, "org", "", "Play in every repository of an `organization`")
+	cmd.Flags().StringVar(&opts.Hostname, "hostname", "", "The GitHub `host` for repositories given without one")
+	cmd.Flags().IntVar(&opts.MaxPages, "max-pages", 0, "Fetch at most this many pages of 100 for each query")
+	cmd.Flags().DurationVar(&opts.CacheTTL, "cache-ttl", defaultCacheTTL, "How long fetched issues and commits are reused for")
+	cmd.Flags().BoolVar(&opts.Refresh, "refresh", false, "Ignore cached issues and commits")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Only use cached issues and commits")
+	cmd.Flags().BoolVarP(&opts.Local, "local", "l", false, "Play offline using the current git repository's history")
+	cmd.Flags().StringVar(&opts.DataFile, "data", "", "Play against issues and commits loaded from a JSON or YAML `file`")
+	cmd.Flags().BoolVar(&opts.PullRequests, "prs", false, "Also play against open pull requests")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 0, "Play the board generated from this `number` again")
+	cmd.Flags().StringVar(&opts.Record, "record", "", "Save a replay of the game to `file`")
+	cmd.Flags().StringVar(&opts.Difficulty, "difficulty", "", fmt.Sprintf("How fast the game plays: {%s}", strings.Join(difficultyNames, "|")))
+	addFilterFlags(cmd, &opts.Filter)
+	addCommitFlags(cmd, &opts.Commits)
 	cmd.Flags().BoolVarP(&opts.Debug, "debug", "d", false, "enable logging")
 
 	return cmd
 }
 
-func runMC(opts mcOpts) error {
-	debug := opts.Debug
+// hostname picks the GitHub host to use for repositories that don't name one,
+// honoring GH_HOST like gh does.
+func hostname(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if h := os.Getenv("GH_HOST"); h != "" {
+		return h
+	}
+	return defaultHost
+}
 
-	var logger *log.Logger
-	if debug {
-		// TODO seriously do a tempfile
-		f, _ := os.Create("mclog.txt")
-		logger = log.New(f, "", log.Lshortfile)
-		logger.Println("mc logging")
+// normalizeRepos validates repos and qualifies them with host where needed.
+func normalizeRepos(repos []string, host string) ([]string, error) {
+	out := []string{}
+	for _, repo := range repos {
+		ref, err := parseRepo(repo, host)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ref.String())
+	}
+	return out, nil
+}
+
+func addFilterFlags(cmd *cobra.Command, filter *issueFilter) {
+	cmd.Flags().StringSliceVar(&filter.Labels, "label", nil, "Only include issues with this `label` (repeatable)")
+	cmd.Flags().StringVar(&filter.Assignee, "assignee", "", "Only include issues assigned to `login`")
+	cmd.Flags().StringVar(&filter.Milestone, "milestone", "", "Only include issues in the milestone with this `title`")
+	cmd.Flags().StringVar(&filter.Search, "search", "", "Only include issues matching this search `query`")
+}
+
+func addCommitFlags(cmd *cobra.Command, r *commitRange) {
+	cmd.Flags().StringVar(&r.Ref, "ref", "", "Use commits from this branch, tag or `sha` instead of the default branch")
+	cmd.Flags().Var((*dateValue)(&r.Since), "since", "Only use commits made on or after this `date`")
+	cmd.Flags().Var((*dateValue)(&r.Until), "until", "Only use commits made before this `date`")
+	cmd.Flags().IntVar(&r.Max, "max-commits", 0, "Use at most this many of the newest commits")
+}
+
+// dateValue is a flag holding a date like 2021-09-01.
+type dateValue time.Time
+
+func (d *dateValue) Set(s string) error {
+	t, err := parseDate(s)
+	if err != nil {
+		return err
 	}
+	*d = dateValue(t)
+	return nil
+}
+
+func (d *dateValue) String() string {
+	t := time.Time(*d)
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func (d *dateValue) Type() string {
+	return "date"
+}
+
+func cacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "manage the issues and commits kept from earlier games",
+	}
+
+	var host string
+	clearCmd := &cobra.Command{
+		Use:   "clear [<repository>]",
+		Short: "remove cached data for one repository, or for all of them",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo := ""
+			if len(args) > 0 {
+				ref, err := parseRepo(args[0], hostname(host))
+				if err != nil {
+					return err
+				}
+				repo = ref.String()
+			}
+			return clearCache(repo)
+		},
+	}
+	clearCmd.Flags().StringVar(&host, "hostname", "", "The GitHub `host` if the repository doesn't name one")
+	cmd.AddCommand(clearCmd)
+
+	return cmd
+}
+
+type exportOpts struct {
+	Repository string
+	Hostname   string
+	Output     string
+	Filter     issueFilter
+	Commits    commitRange
+}
+
+func exportCmd() *cobra.Command {
+	opts := exportOpts{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "save a repository's issues, pull requests and commits to a file for use with --data",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Repository == "" {
+				repo, err := resolveRepository(hostname(opts.Hostname))
+				if err != nil {
+					return err
+				}
+				opts.Repository = repo
+			}
+			ref, err := parseRepo(opts.Repository, hostname(opts.Hostname))
+			if err != nil {
+				return err
+			}
+			opts.Repository = ref.String()
+			return runExport(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Repository, "repo", "R", "", "Repository to export")
+	cmd.Flags().StringVar(&opts.Hostname, "hostname", "", "The GitHub `host` if the repository doesn't name one")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Write to `file` instead of STDOUT")
+	addFilterFlags(cmd, &opts.Filter)
+	addCommitFlags(cmd, &opts.Commits)
 
-	rand.Seed(time.Now().UTC().UnixNano())
+	return cmd
+}
 
-	issues, err := getIssues(opts.Repository)
+func runExport(opts exportOpts) error {
+	source := ghSource{Filter: opts.Filter, Commits: opts.Commits}
+
+	issues, err := source.Issues(opts.Repository)
 	if err != nil {
 		return fmt.Errorf("failed to get issues for %s: %w", opts.Repository, err)
 	}
 
-	shas, err := getSHAs(opts.Repository)
+	prs, err := source.PullRequests(opts.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to get pull requests for %s: %w", opts.Repository, err)
+	}
+
+	shas, err := source.SHAs(opts.Repository)
 	if err != nil {
 		return fmt.Errorf("failed to get shas for %s: %w", opts.Repository, err)
 	}
 
-	rand.Shuffle(len(issues), func(i, j int) {
-		issues[i], issues[j] = issues[j], issues[i]
+	return writeFixture(opts.Output, &fixture{
+		Repository:   opts.Repository,
+		Issues:       issues,
+		PullRequests: prs,
+		Commits:      shas,
 	})
+}
 
-	style := tcell.StyleDefault
-
+// startScreen takes over the terminal, forwarding its input to the returned
+// channel until it's finalized.
+func startScreen(style tcell.Style) (tcell.Screen, <-chan tcell.Event, error) {
 	s, err := tcell.NewScreen()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if err = s.Init(); err != nil {
-		return err
+		return nil, nil, err
 	}
 	s.SetStyle(style)
 
 	w, h := s.Size()
 	if w < minWidth || h < minHeight {
-		return errors.New("screen too small, need 80x20 at least.")
+		s.Fini()
+		return nil, nil, errors.New("screen too small, need 80x20 at least.")
 	}
 
-	game := &Game{
-		Repo:     opts.Repository,
-		debug:    debug,
-		Screen:   s,
-		Style:    style,
-		MaxWidth: minWidth,
-		Logger:   logger,
+	events := make(chan tcell.Event)
+	go func() {
+		for {
+			ev := s.PollEvent()
+			if ev == nil {
+				// screen was finalized
+				return
+			}
+			events <- ev
+		}
+	}()
+
+	return s, events, nil
+}
+
+func replayCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay FILE",
+		Short: "watch a game saved with --record",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadReplay(args[0])
+			if err != nil {
+				return err
+			}
+			return runReplay(r)
+		},
 	}
+}
 
-	err = game.LoadState()
+func runReplay(r *replay) error {
+	style := tcell.StyleDefault
+
+	s, events, err := startScreen(style)
 	if err != nil {
-		game.Debugf("failed to load state: %s", err)
+		return err
 	}
 
-	issueSpawners := []*IssueSpawner{}
-	y := 2
-	x := 0
-	for i := 0; i < 10; i++ {
-		if i%2 == 0 {
-			x = 0
-		} else {
-			x = game.MaxWidth
-		}
+	game := &Game{
+		Repo:           r.Repo,
+		Repos:          r.Repos,
+		Screen:         s,
+		Style:          style,
+		MaxWidth:       minWidth,
+		State:          &stateEntry{HighScores: map[string][]scoreEntry{}},
+		Config:         r.Config,
+		Events:         events,
+		Seed:           r.Seed,
+		Difficulty:     r.Difficulty,
+		DifficultyName: r.DifficultyName,
+	}
+	game.Setup(r.Commits)
+	game.Playback(r)
 
-		is := NewIssueSpawner(x, y+i, game)
+	s.Fini()
 
-		issueSpawners = append(issueSpawners, is)
-		game.AddDrawable(is)
+	fmt.Printf("replayed score: %d, recorded score: %d\n", game.Score(), r.Score)
+	if game.Score() != r.Score && game.ticks == r.Ticks {
+		return errors.New("replay did not reproduce the recorded score")
 	}
 
-	for ix, issueText := range issues {
-		spawnerIx := ix % len(issueSpawners)
-		issueSpawners[spawnerIx].AddIssue(issueText)
+	return nil
+}
+
+func runMC(opts mcOpts) error {
+	debug := opts.Debug
+
+	var logger *log.Logger
+	if debug {
+		// TODO seriously do a tempfile
+		f, _ := os.Create("mclog.txt")
+		logger = log.New(f, "", log.Lshortfile)
+		logger.Println("mc logging")
 	}
 
-	cl := NewCommitLauncher(game, shas)
-	cl.Transform(37, 13)
-	game.AddDrawable(cl)
+	style := tcell.StyleDefault
 
-	cc := NewCommitCounter(35, 14, cl, game)
-	game.AddDrawable(cc)
+	s, events, err := startScreen(style)
+	if err != nil {
+		return err
+	}
 
-	score := NewScore(38, 18, game)
-	game.AddDrawable(score)
+	feed := loadGameData(opts)
 
-	scoreLog := NewScoreLog(15, 15, game)
-	game.AddDrawable(scoreLog)
+	progress := opts.Progress
+	if progress == nil {
+		progress = &loadProgress{}
+	}
+	cancelled, err := showLoading(s, style, scoreKey(opts), progress, events, feed.Ready)
+	if cancelled || err != nil {
+		feed.Stop()
+		s.Fini()
+		return err
+	}
 
-	game.AddDrawable(NewLegend(1, 15, game))
+	game := &Game{
+		Repo:     scoreKey(opts),
+		Repos:    feed.Repos,
+		debug:    debug,
+		Screen:   s,
+		Style:    style,
+		MaxWidth: minWidth,
+		Logger:   logger,
+		Events:   events,
+		Seed:     opts.Seed,
+	}
 
-	highScores := NewHighScores(60, 15, game)
-	game.AddDrawable(highScores)
+	err = game.LoadState()
+	if err != nil {
+		game.Debugf("failed to load state: %s", err)
+	}
 
-	quit := make(chan struct{})
-	go func() {
-		for {
-			ev := s.PollEvent()
-			switch ev := ev.(type) {
-			case *tcell.EventKey:
-				switch ev.Rune() {
-				case ' ':
-					cl.Launch()
-				case 'q':
-					close(quit)
-					return
-				}
-				switch ev.Key() {
-				case tcell.KeyEscape:
-					close(quit)
-					return
-				case tcell.KeyCtrlL:
-					s.Sync()
-				case tcell.KeyLeft:
-					if cl.x > 0 {
-						cl.Transform(-1, 0)
-					}
-				case tcell.KeyRight:
-					if cl.w+cl.x < game.MaxWidth {
-						cl.Transform(1, 0)
+	err = game.LoadConfig()
+	if err != nil {
+		feed.Stop()
+		s.Fini()
+		return err
+	}
 
-					}
-				}
-			case *tcell.EventResize:
-				s.Sync()
-			}
-			if len(cl.Shas) == 0 {
-				close(quit)
-				return
-			}
-			spawnersEmpty := true
-			for _, s := range issueSpawners {
-				if len(s.issues) > 0 {
-					spawnersEmpty = false
-					break
-				}
-			}
+	name, d, err := game.Config.ResolveDifficulty(opts.Difficulty)
+	if err != nil {
+		feed.Stop()
+		s.Fini()
+		return err
+	}
+	game.Difficulty = d
+	game.DifficultyName = name
 
-			issuesRemain := false
-			_ = game.FilterGameObjects(func(gobj Drawable) bool {
-				_, ok := gobj.(*Issue)
-				if ok {
-					issuesRemain = true
-					return false
-				}
-				return true
-			})
+	game.Setup(feed.SHAs)
+	if opts.Record != "" {
+		game.Record()
+	}
+	game.Run(feed.Issues)
+	feed.Stop()
 
-			if spawnersEmpty && !issuesRemain {
-				close(quit)
-				return
-			}
-		}
-	}()
+	s.Fini()
 
-loop:
-	for {
-		select {
-		case <-quit:
-			break loop
-		case <-time.After(time.Millisecond * 100):
-		}
+	if err := feed.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: stopped fetching issues early: %s\n", err)
+	}
 
-		s.Clear()
-		spawner := issueSpawners[rand.Intn(len(issueSpawners))]
-		spawner.Spawn()
-		game.Update()
-		game.Draw()
-		titleStyle := style.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
-		title := "!!! M E R G E  C O N F L I C T !!!"
-		drawStr(s, 25, 0, titleStyle, title)
-		drawStr(s, 25+len(title)+3, 0, style, fmt.Sprintf("np: %s", opts.Repository))
-		s.Show()
+	if opts.Record != "" {
+		err = writeReplay(opts.Record, game.Recording())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		} else {
+			fmt.Printf("saved replay to %s\n", opts.Record)
+		}
 	}
 
-	s.Fini()
+	printTriageReport(game)
+	// unseeded games deal issues as they arrive, so only a seeded game's
+	// board can be dealt again
+	if opts.Seeded {
+		fmt.Printf("seed: %d (play this board again with --seed)\n", opts.Seed)
+	}
 
 	// TODO this following code is very bad, abstract to function and clean up
 	// TODO GetState helper on Game
-	_, ok := game.State.HighScores[opts.Repository]
+	key := game.HighScoreKey()
+	_, ok := game.State.HighScores[key]
 	if !ok {
-		game.State.HighScores[opts.Repository] = []scoreEntry{}
+		game.State.HighScores[key] = []scoreEntry{}
 	}
 
 	game.Debugf("%#v\n", game.State.HighScores)
 
 	maxScore := 0
-	for _, v := range game.State.HighScores[opts.Repository] {
+	for _, v := range game.State.HighScores[key] {
 		if v.Score > maxScore {
 			maxScore = v.Score
 		}
 	}
 
 	game.Debugf("%#v\n", maxScore)
-	game.Debugf("%#v\n", score.score)
+	game.Debugf("%#v\n", game.Score())
 
-	if score.score >= maxScore && score.score > 0 {
+	if game.Score() >= maxScore && game.Score() > 0 {
 		answer := false
 		err = survey.AskOne(
 			&survey.Confirm{
@@ -260,10 +637,10 @@ loop:
 				}, &answer)
 			if err == nil {
 				game.Debugf("ABOUT TO SET HIGH SCORE")
-				game.Debugf("%#v %s %d", game.State, answer, score.score)
-				game.State.HighScores[opts.Repository] = append(game.State.HighScores[opts.Repository], scoreEntry{
+				game.Debugf("%#v %s %d", game.State, answer, game.Score())
+				game.State.HighScores[key] = append(game.State.HighScores[key], scoreEntry{
 					Name:  answer,
-					Score: score.score,
+					Score: game.Score(),
 				})
 				err = game.SaveState()
 				game.Debugf("%#v", game.State)
@@ -274,14 +651,67 @@ loop:
 		}
 	}
 
+	// issues the game never got to are still worth caching for next time
+	select {
+	case <-feed.Done():
+	default:
+		fmt.Fprintln(os.Stderr, "caching the rest of the issues for next time...")
+		<-feed.Done()
+	}
+
 	return nil
 }
 
+// scoreKey is what high scores are saved under: the repository, or the set of
+// repositories, that was played.
+func scoreKey(opts mcOpts) string {
+	if opts.Org != "" {
+		return repoRef{Host: opts.Hostname, Owner: opts.Org, Name: "*"}.String()
+	}
+	repos := append([]string{}, opts.Repositories...)
+	sort.Strings(repos)
+	return strings.Join(repos, ",")
+}
+
+// interleave merges lists by taking one item from each in turn.
+func interleave(lists [][]string) []string {
+	out := []string{}
+	for i := 0; ; i++ {
+		added := false
+		for _, l := range lists {
+			if i < len(l) {
+				out = append(out, l[i])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
+
+func printTriageReport(game *Game) {
+	if game.Overwhelmed() {
+		fmt.Println("too many issues got away and your backlog overflowed!")
+	}
+	if len(game.Triaged) == 0 {
+		return
+	}
+	fmt.Printf("you triaged %d issues:\n", len(game.Triaged))
+	for _, issue := range game.Triaged {
+		fmt.Printf("  %s\n", issue.Summary())
+	}
+}
+
 func main() {
 	rc := rootCmd()
 
 	if err := rc.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
-		os.Exit(1)
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.Hint() != "" {
+			fmt.Fprintf(os.Stderr, "%s\n", apiErr.Hint())
+		}
+		os.Exit(exitCode(err))
 	}
 }
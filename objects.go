@@ -2,21 +2,43 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+// pull requests have already had work put into them, so they're worth more
+const pullRequestValue = 2
+
 type Issue struct {
 	GameObject
-	dir Direction
+	dir      Direction
+	Entry    issueEntry
+	behavior labelBehavior
+	travel   float64 // distance owed but not yet moved
+	ticks    int
+	style    tcell.Style
 }
 
-func NewIssue(x, y int, dir Direction, text string, game *Game) *Issue {
+func NewIssue(x, y int, dir Direction, entry issueEntry, game *Game) *Issue {
+	text := entry.String()
 	// I disabled the background because i didn't like how spaces left behind had gray behind them while spaces between issues were black. mainly i just want it to be consistent.
 	style := game.Style.Foreground(tcell.ColorWhite) //.Background(tcell.ColorBlack)
+	if entry.Repo != "" {
+		style = game.Style.Foreground(game.RepoColor(entry.Repo))
+	}
+	behavior := game.BehaviorFor(entry)
+	behavior.Speed *= game.Difficulty.IssueSpeed
+	if entry.PullRequest {
+		style = game.Style.Foreground(tcell.ColorGreen)
+		behavior.Value *= pullRequestValue
+	}
 	return &Issue{
-		dir: dir,
+		dir:      dir,
+		Entry:    entry,
+		behavior: behavior,
+		style:    style,
 		GameObject: GameObject{
 			x:             x,
 			y:             y,
@@ -30,17 +52,57 @@ func NewIssue(x, y int, dir Direction, text string, game *Game) *Issue {
 }
 
 func (i *Issue) Update() {
-	i.Transform(int(i.dir), 0)
+	i.ticks++
+	i.travel += i.behavior.Speed
+	for i.travel >= 1 {
+		i.Transform(int(i.dir), 0)
+		i.travel--
+	}
+
+	if i.behavior.Flash {
+		style := i.style
+		if i.ticks%2 == 0 {
+			style = style.Background(tcell.ColorRed)
+		}
+		i.StyleOverride = &style
+	}
+
 	if i.dir > 0 && i.x > 5+i.Game.MaxWidth {
 		// hoping this is enough for GC to claim
-		i.Game.Destroy(i)
+		i.Escape()
 	}
 
 	if i.dir < 0 && i.x < -5-len(i.Sprite) {
-		i.Game.Destroy(i)
+		i.Escape()
 	}
 }
 
+// Escape removes an issue that made it off screen. Unless it was already shot
+// away it charges any penalty its labels carry, and every letter left on it
+// piles onto the backlog.
+func (i *Issue) Escape() {
+	i.Game.Destroy(i)
+	if i.Cleared() {
+		return
+	}
+	if i.behavior.Penalty > 0 {
+		i.Game.AddScore(-i.behavior.Penalty, false)
+	}
+	// wontfix issues were never going to be triaged
+	if !i.Indestructible() {
+		i.Game.health.Damage(i.Remaining())
+	}
+}
+
+// Value is the number of points a single letter of this issue is worth.
+func (i *Issue) Value() float64 {
+	return i.behavior.Value
+}
+
+func (i *Issue) Indestructible() bool {
+	return i.behavior.Indestructible
+}
+
 func (i *Issue) LetterAt(x int) rune {
 	return rune(i.Sprite[x])
 }
@@ -57,11 +119,21 @@ func (i *Issue) DestroyLetterAt(x int) {
 	i.Sprite = newSprite
 }
 
+// Remaining is the number of letters not yet shot away.
+func (i *Issue) Remaining() int {
+	return len(strings.ReplaceAll(i.Sprite, " ", ""))
+}
+
+// Cleared reports whether every letter of the issue has been shot away.
+func (i *Issue) Cleared() bool {
+	return strings.TrimSpace(i.Sprite) == ""
+}
+
 // would be nice to just call "spawn" at random intervals but have the spawner lock itself if it's already got something still going
 // how should it track if it's active?
 type IssueSpawner struct {
 	GameObject
-	issues    []string
+	issues    []issueEntry
 	countdown int
 }
 
@@ -86,10 +158,9 @@ func (is *IssueSpawner) Spawn() {
 		return
 	}
 
-	issueText := is.issues[0]
+	issue := is.issues[0]
 	is.issues = is.issues[1:]
-
-	is.countdown = len(issueText) + 3 // add arbitrary cool off
+	issueText := issue.String()
 
 	// is.x is either 0 or maxwidth
 	x := is.x
@@ -100,10 +171,15 @@ func (is *IssueSpawner) Spawn() {
 		dir = 1
 	}
 
-	is.Game.AddDrawable(NewIssue(x, is.y, dir, issueText, is.Game))
+	spawned := NewIssue(x, is.y, dir, issue, is.Game)
+	is.Game.AddDrawable(spawned)
+
+	// let it clear the spawner at its own speed, then cool off
+	frames := int(math.Ceil(float64(len(issueText)) / spawned.behavior.Speed))
+	is.countdown = frames + is.Game.Difficulty.SpawnCooldown
 }
 
-func (is *IssueSpawner) AddIssue(issue string) {
+func (is *IssueSpawner) AddIssue(issue issueEntry) {
 	is.issues = append(is.issues, issue)
 }
 
@@ -235,7 +311,7 @@ func (cl *CommitLauncher) Launch() {
 	if cl.cooldown > 0 {
 		return
 	}
-	cl.cooldown = 4
+	cl.cooldown = cl.Game.Difficulty.LauncherCooldown
 
 	if len(cl.Shas) == 0 {
 		return
@@ -340,7 +416,7 @@ q:     quit`,
 
 func NewHighScores(x, y int, g *Game) *GameObject {
 	sprite := "~* high scores *~"
-	highScores, ok := g.State.HighScores[g.Repo]
+	highScores, ok := g.State.HighScores[g.HighScoreKey()]
 	if ok {
 		for x := len(highScores) - 1; x >= 0; x-- {
 			sprite += fmt.Sprintf("\n%s %d", highScores[x].Name, highScores[x].Score)
@@ -354,6 +430,66 @@ func NewHighScores(x, y int, g *Game) *GameObject {
 	}
 }
 
+// BacklogHealth drains as issues escape untriaged. The game is lost when it
+// runs out.
+type BacklogHealth struct {
+	GameObject
+	health int
+	max    int
+}
+
+const backlogBarWidth = 10
+
+func NewBacklogHealth(x, y, max int, game *Game) *BacklogHealth {
+	bh := &BacklogHealth{
+		health: max,
+		max:    max,
+		GameObject: GameObject{
+			x:    x,
+			y:    y,
+			h:    1,
+			Game: game,
+		},
+	}
+	bh.Update()
+	return bh
+}
+
+func (bh *BacklogHealth) Damage(n int) {
+	bh.health -= n
+	if bh.health < 0 {
+		bh.health = 0
+	}
+	// issues escape after we've updated for the frame
+	bh.Update()
+}
+
+func (bh *BacklogHealth) Empty() bool {
+	return bh.health == 0
+}
+
+func (bh *BacklogHealth) Update() {
+	filled := 0
+	if bh.max > 0 {
+		filled = (bh.health*backlogBarWidth + bh.max - 1) / bh.max
+	}
+	sprite := fmt.Sprintf("BACKLOG [%s%s]",
+		strings.Repeat("#", filled),
+		strings.Repeat("-", backlogBarWidth-filled))
+
+	style := bh.Game.Style.Foreground(tcell.ColorGreen)
+	switch {
+	case bh.health*4 <= bh.max:
+		style = bh.Game.Style.Foreground(tcell.ColorRed)
+	case bh.health*2 <= bh.max:
+		style = bh.Game.Style.Foreground(tcell.ColorYellow)
+	}
+
+	bh.Sprite = sprite
+	bh.w = len(sprite)
+	bh.StyleOverride = &style
+}
+
 type ScoreLog struct {
 	GameObject
 	log []string
@@ -379,7 +515,9 @@ func (sl *ScoreLog) Update() {
 
 func (sl *ScoreLog) Log(value int, get bool) {
 	msg := fmt.Sprintf("%d points!", value)
-	if get {
+	if value < 0 {
+		msg = fmt.Sprintf("%d points :(", value)
+	} else if get {
 		msg = fmt.Sprintf("%d points BONUS GET!", value)
 	}
 	sl.log = append(sl.log, msg)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// lowQuota is the number of remaining requests below which we start
+// rationing pages.
+const lowQuota = 500
+
+type rateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     int64
+}
+
+func (r rateLimit) ResetAt() time.Time {
+	return time.Unix(r.Reset, 0)
+}
+
+// getRateLimit reports whichever of the REST and GraphQL quotas on host is
+// closer to running out. Checking it doesn't count against either.
+func getRateLimit(host string) (rateLimit, error) {
+	c, err := clientFor(host)
+	if err != nil {
+		return rateLimit{}, err
+	}
+
+	var resp struct {
+		Resources struct {
+			Core    rateLimit
+			GraphQL rateLimit
+		}
+	}
+	err = c.REST("rate_limit", nil, &resp)
+	if err != nil {
+		return rateLimit{}, err
+	}
+
+	core, graphQL := resp.Resources.Core, resp.Resources.GraphQL
+	if core.Limit == 0 && graphQL.Limit == 0 {
+		return rateLimit{}, errors.New("no rate limit information")
+	}
+	if core.Limit == 0 || graphQL.Limit > 0 && graphQL.Remaining < core.Remaining {
+		return graphQL, nil
+	}
+	return core, nil
+}
+
+// pageBudget caps how many pages each of queries paginated fetches may use so
+// that together they leave at least half of the remaining quota alone. It
+// returns 0 (no cap) while quota is plentiful, and never less than a page
+// otherwise; an exhausted quota needs handling before it gets here.
+func pageBudget(r rateLimit, queries int) int {
+	if r.Remaining >= lowQuota || queries == 0 {
+		return 0
+	}
+	budget := r.Remaining / 2 / queries
+	if budget < 1 {
+		budget = 1
+	}
+	return budget
+}
+
+func isRateLimited(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Kind == apiErrRateLimited
+}
+
+// isSecondaryRateLimit reports whether err is GitHub asking us to slow down
+// rather than telling us we're out of quota.
+func isSecondaryRateLimit(err error) bool {
+	return isRateLimited(err) && strings.Contains(strings.ToLower(err.Error()), "secondary rate limit")
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestPageBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining int
+		queries   int
+		want      int
+	}{
+		{"plenty", 5000, 3, 0},
+		{"just enough", lowQuota, 3, 0},
+		{"low", 300, 3, 50},
+		{"split unevenly", 100, 3, 16},
+		{"nearly out", 3, 3, 1},
+		{"no queries", 100, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageBudget(rateLimit{Limit: 5000, Remaining: tt.remaining}, tt.queries)
+			if got != tt.want {
+				t.Errorf("got %d pages, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+type gitRemote struct {
+	Name string
+	Repo repoRef
+}
+
+// resolveRepository works out which repository to play in from the git
+// remotes of the current directory, asking which one to use if the remotes
+// point at more than one repository. Only remotes on github.com, on host or
+// on a host gh is authenticated with count.
+func resolveRepository(host string) (string, error) {
+	sout, eout, err := git("remote", "-v")
+	if err != nil {
+		if strings.Contains(eout.String(), "not a git repository") {
+			return "", errors.New("Try running this command from inside a git repository or with the -R flag")
+		}
+		return "", err
+	}
+
+	known := map[string]bool{defaultHost: true, host: true}
+	for h := range ghHosts() {
+		known[h] = true
+	}
+
+	remotes := parseRemotes(sout.String(), known)
+	switch len(remotes) {
+	case 0:
+		return "", errors.New("could not find a GitHub remote; try running this command with the -R flag")
+	case 1:
+		return remotes[0].Repo.String(), nil
+	}
+
+	options := []string{}
+	for _, r := range remotes {
+		options = append(options, fmt.Sprintf("%s (%s)", r.Repo, r.Name))
+	}
+
+	var picked int
+	err = survey.AskOne(
+		&survey.Select{
+			Message: "which repository do you want to play in?",
+			Options: options,
+			Default: options[0],
+		}, &picked)
+	if err != nil {
+		return "", fmt.Errorf("could not pick a repository; try running this command with the -R flag: %w", err)
+	}
+
+	return remotes[picked].Repo.String(), nil
+}
+
+// parseRemotes turns the output of `git remote -v` into one entry per
+// distinct repository on one of the known hosts, ordered so upstream comes
+// before origin before anything else.
+func parseRemotes(out string, known map[string]bool) []gitRemote {
+	remotes := []gitRemote{}
+	seen := map[repoRef]bool{}
+	for _, line := range splitLines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		repo, err := parseRemoteURL(fields[1])
+		if err != nil || !known[repo.Host] || seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		remotes = append(remotes, gitRemote{Name: fields[0], Repo: repo})
+	}
+
+	rank := func(name string) int {
+		switch name {
+		case "upstream":
+			return 0
+		case "origin":
+			return 1
+		}
+		return 2
+	}
+	ordered := []gitRemote{}
+	for r := 0; r <= 2; r++ {
+		for _, remote := range remotes {
+			if rank(remote.Name) == r {
+				ordered = append(ordered, remote)
+			}
+		}
+	}
+
+	return ordered
+}
+
+// parseRemoteURL understands https and ssh remote URLs as well as the scp
+// like git@host:owner/repo syntax.
+func parseRemoteURL(remote string) (repoRef, error) {
+	if !strings.Contains(remote, "://") {
+		at := strings.Index(remote, "@")
+		colon := strings.Index(remote, ":")
+		if colon < 0 || colon < at {
+			return repoRef{}, fmt.Errorf("unsupported remote %q", remote)
+		}
+		remote = "ssh://" + remote[:colon] + "/" + remote[colon+1:]
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return repoRef{}, err
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if u.Hostname() == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return repoRef{}, fmt.Errorf("unsupported remote %q", remote)
+	}
+
+	// github.com can be reached over ssh on port 443 through ssh.github.com
+	host := u.Hostname()
+	if strings.EqualFold(host, "ssh."+defaultHost) {
+		host = defaultHost
+	}
+	return repoRef{Host: host, Owner: parts[0], Name: parts[1]}, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   repoRef
+	}{
+		{"https://github.com/cli/cli.git", repoRef{Host: "github.com", Owner: "cli", Name: "cli"}},
+		{"https://github.com/cli/cli", repoRef{Host: "github.com", Owner: "cli", Name: "cli"}},
+		{"git@github.com:cli/cli.git", repoRef{Host: "github.com", Owner: "cli", Name: "cli"}},
+		{"ssh://git@github.com/cli/cli.git", repoRef{Host: "github.com", Owner: "cli", Name: "cli"}},
+		{"ssh://git@ssh.github.com:443/cli/cli.git", repoRef{Host: "github.com", Owner: "cli", Name: "cli"}},
+		{"git@ssh.corp.example.com:o/r.git", repoRef{Host: "ssh.corp.example.com", Owner: "o", Name: "r"}},
+		{"https://ghe.example.com/o/r", repoRef{Host: "ghe.example.com", Owner: "o", Name: "r"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			got, err := parseRemoteURL(tt.remote)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, remote := range []string{"/srv/git/cli.git", "https://github.com/cli", "https://github.com/cli/cli/tree"} {
+		if _, err := parseRemoteURL(remote); err == nil {
+			t.Errorf("expected an error for %q", remote)
+		}
+	}
+}
+
+func TestParseRemotes(t *testing.T) {
+	out := `fork	git@github.com:me/cli.git (fetch)
+fork	git@github.com:me/cli.git (push)
+origin	https://github.com/vilmibm/cli.git (fetch)
+origin	https://github.com/vilmibm/cli.git (push)
+gitlab	git@gitlab.com:vilmibm/cli.git (fetch)
+gitlab	git@gitlab.com:vilmibm/cli.git (push)
+work	git@ghe.example.com:corp/cli.git (fetch)
+work	git@ghe.example.com:corp/cli.git (push)
+upstream	https://github.com/cli/cli.git (fetch)
+upstream	https://github.com/cli/cli.git (push)
+`
+	known := map[string]bool{"github.com": true, "ghe.example.com": true}
+
+	got := []string{}
+	for _, r := range parseRemotes(out, known) {
+		got = append(got, r.Name+" "+r.Repo.String())
+	}
+	want := []string{
+		"upstream cli/cli",
+		"origin vilmibm/cli",
+		"fork me/cli",
+		"work ghe.example.com/corp/cli",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const replayVersion = 1
+
+// replay is everything needed to play a game back frame for frame: the seed,
+// the data it was played with and every key the player pressed.
+type replay struct {
+	Version int          `json:"version"`
+	Repo    string       `json:"repo"`
+	Repos   []string     `json:"repos,omitempty"`
+	Seed    int64        `json:"seed"`
+	Config  *configEntry `json:"config,omitempty"`
+	// Difficulty is the pace the game was played at.
+	Difficulty     difficulty `json:"difficulty"`
+	DifficultyName string     `json:"difficultyName,omitempty"`
+	Commits        []string   `json:"commits"`
+	// Batches are the issues in the order they arrived.
+	Batches []replayBatch `json:"batches"`
+	Events  []replayEvent `json:"events"`
+	// Ticks is how long the game lasted.
+	Ticks int64 `json:"ticks"`
+	// Score is what the player finished with, to check playback against.
+	Score int `json:"score"`
+}
+
+type replayBatch struct {
+	Tick   int64         `json:"tick"`
+	Issues []replayIssue `json:"issues"`
+}
+
+// replayIssue keeps the fields fixtures leave out of issueEntry.
+type replayIssue struct {
+	issueEntry
+	Repo        string `json:"repo,omitempty"`
+	PullRequest bool   `json:"pullRequest,omitempty"`
+}
+
+// replayEvent is a key press along with the tick it happened on.
+type replayEvent struct {
+	Tick int64     `json:"tick"`
+	Key  tcell.Key `json:"key"`
+	Rune rune      `json:"rune,omitempty"`
+}
+
+func (e replayEvent) event() tcell.Event {
+	return tcell.NewEventKey(e.Key, e.Rune, tcell.ModNone)
+}
+
+// Record starts recording the game. Call it after Setup.
+func (g *Game) Record() {
+	g.recording = &replay{
+		Version:        replayVersion,
+		Repo:           g.Repo,
+		Repos:          g.Repos,
+		Seed:           g.Seed,
+		Config:         g.Config,
+		Difficulty:     g.Difficulty,
+		DifficultyName: g.DifficultyName,
+		Commits:        append([]string{}, g.launcher.Shas...),
+	}
+}
+
+// Recording returns what has been recorded so far, or nil if the game isn't
+// being recorded.
+func (g *Game) Recording() *replay {
+	if g.recording == nil {
+		return nil
+	}
+	g.recording.Ticks = g.ticks
+	g.recording.Score = g.Score()
+	return g.recording
+}
+
+func (g *Game) recordBatch(issues []issueEntry) {
+	if g.recording == nil {
+		return
+	}
+	batch := replayBatch{Tick: g.ticks}
+	for _, issue := range issues {
+		batch.Issues = append(batch.Issues, replayIssue{
+			issueEntry:  issue,
+			Repo:        issue.Repo,
+			PullRequest: issue.PullRequest,
+		})
+	}
+	g.recording.Batches = append(g.recording.Batches, batch)
+}
+
+func (g *Game) recordEvent(ev tcell.Event) {
+	key, ok := ev.(*tcell.EventKey)
+	if !ok || g.recording == nil {
+		return
+	}
+	g.recording.Events = append(g.recording.Events, replayEvent{
+		Tick: g.ticks,
+		Key:  key.Key(),
+		Rune: key.Rune(),
+	})
+}
+
+// Playback plays a recorded game back frame for frame. The player can stop
+// watching by pressing escape or q.
+func (g *Game) Playback(r *replay) {
+	clock, stop := g.clock()
+	defer stop()
+
+	batches := r.Batches
+	events := r.Events
+	for tick := int64(0); tick < r.Ticks; tick++ {
+		for len(batches) > 0 && batches[0].Tick <= tick {
+			issues := []issueEntry{}
+			for _, issue := range batches[0].Issues {
+				entry := issue.issueEntry
+				entry.Repo = issue.Repo
+				entry.PullRequest = issue.PullRequest
+				issues = append(issues, entry)
+			}
+			g.deal(issues)
+			batches = batches[1:]
+		}
+		for len(events) > 0 && events[0].Tick <= tick {
+			if g.HandleEvent(events[0].event()) {
+				return
+			}
+			events = events[1:]
+		}
+
+		if g.waitForFrame(clock) {
+			return
+		}
+		g.Tick()
+	}
+}
+
+// waitForFrame waits for the next frame while watching the player's input,
+// reporting whether they asked to stop watching.
+func (g *Game) waitForFrame(clock func() <-chan time.Time) (quit bool) {
+	for {
+		select {
+		case ev := <-g.Events:
+			key, ok := ev.(*tcell.EventKey)
+			if ok && (key.Key() == tcell.KeyEscape || key.Rune() == 'q') {
+				return true
+			}
+		case <-clock():
+			return false
+		}
+	}
+}
+
+func loadReplay(path string) (*replay, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay: %w", err)
+	}
+
+	var r replay
+	err = json.Unmarshal(content, &r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse replay %s: %w", path, err)
+	}
+	if r.Version != replayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d in %s", r.Version, path)
+	}
+
+	return &r, nil
+}
+
+func writeReplay(path string, r *replay) error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to serialize replay: %w", err)
+	}
+
+	err = os.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save replay: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestReplayReproducesGame(t *testing.T) {
+	shas := makeSHAs(100)
+	game, s := newTestGameWith(t, testGameOpts{Seed: 1234}, shas)
+	game.Record()
+
+	game.deal(makeIssues(10))
+	for tick := 0; tick < 300; tick++ {
+		if tick == 50 {
+			game.deal(makeIssues(10))
+		}
+		switch {
+		case tick%40 == 0:
+			game.HandleEvent(keyEvent(tcell.KeyLeft))
+		case tick%40 == 20:
+			game.HandleEvent(keyEvent(tcell.KeyRight))
+		case tick%3 == 0:
+			game.HandleEvent(runeEvent(' '))
+		}
+		game.Tick()
+	}
+	recorded := []string{}
+	for y := 0; y < minHeight; y++ {
+		recorded = append(recorded, row(s, y))
+	}
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := writeReplay(path, game.Recording()); err != nil {
+		t.Fatalf("failed to write replay: %s", err)
+	}
+	r, err := loadReplay(path)
+	if err != nil {
+		t.Fatalf("failed to load replay: %s", err)
+	}
+	if r.Score == 0 {
+		t.Fatal("recorded game didn't score; pick a better script")
+	}
+	if len(r.Batches) != 2 || r.Batches[1].Tick != 50 {
+		t.Errorf("got batches %+v, want two with the second on tick 50", r.Batches)
+	}
+
+	playback, ps := newTestGameWith(t, testGameOpts{Seed: r.Seed, Difficulty: r.Difficulty}, r.Commits)
+	now := make(chan time.Time)
+	close(now)
+	playback.Clock = func() <-chan time.Time {
+		return now
+	}
+	playback.Playback(r)
+
+	if playback.Score() != r.Score {
+		t.Errorf("playback scored %d, want %d", playback.Score(), r.Score)
+	}
+	for y := 0; y < minHeight; y++ {
+		if got := row(ps, y); got != recorded[y] {
+			t.Errorf("row %d differs:\n%q\n%q", y, got, recorded[y])
+		}
+	}
+}
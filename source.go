@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// DataSource provides the issues and pull requests to shoot at and the commit
+// SHAs to shoot them with for a given repository.
+type DataSource interface {
+	Issues(repo string) ([]issueEntry, error)
+	PullRequests(repo string) ([]issueEntry, error)
+	SHAs(repo string) ([]string, error)
+}
+
+// issueStreamer is implemented by data sources that can deliver issues and
+// pull requests a page at a time instead of all at once.
+type issueStreamer interface {
+	StreamIssues(repo string, send func([]issueEntry)) error
+	StreamPullRequests(repo string, send func([]issueEntry)) error
+}
+
+// streamIssues hands the issues of repo to send a page at a time if source
+// supports that, or all at once if it doesn't.
+func streamIssues(source DataSource, repo string, send func([]issueEntry)) error {
+	if streamer, ok := source.(issueStreamer); ok {
+		return streamer.StreamIssues(repo, send)
+	}
+	issues, err := source.Issues(repo)
+	if err != nil {
+		return err
+	}
+	send(issues)
+	return nil
+}
+
+// streamPullRequests is streamIssues for pull requests.
+func streamPullRequests(source DataSource, repo string, send func([]issueEntry)) error {
+	if streamer, ok := source.(issueStreamer); ok {
+		return streamer.StreamPullRequests(repo, send)
+	}
+	prs, err := source.PullRequests(repo)
+	if err != nil {
+		return err
+	}
+	send(prs)
+	return nil
+}
+
+// collectIssues gathers up every page a stream sends.
+func collectIssues(stream func(send func([]issueEntry)) error) ([]issueEntry, error) {
+	out := []issueEntry{}
+	err := stream(func(page []issueEntry) {
+		out = append(out, page...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type issueEntry struct {
+	Number    int       `json:"number" yaml:"number"`
+	Title     string    `json:"title" yaml:"title"`
+	Labels    []string  `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Author    string    `json:"author,omitempty" yaml:"author,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty" yaml:"createdAt,omitempty"`
+	Comments  int       `json:"comments,omitempty" yaml:"comments,omitempty"`
+	Reactions int       `json:"reactions,omitempty" yaml:"reactions,omitempty"`
+
+	// PullRequest is set for pull requests, which are stored separately from
+	// issues in fixtures.
+	PullRequest bool `json:"-" yaml:"-"`
+	// Repo is set when playing across several repositories.
+	Repo string `json:"-" yaml:"-"`
+}
+
+// String is the text an issue is drawn with in game.
+func (i issueEntry) String() string {
+	if i.Number == 0 {
+		return i.Title
+	}
+	ref := fmt.Sprintf("#%d", i.Number)
+	if i.Repo != "" {
+		ref = path.Base(i.Repo) + ref
+	}
+	if i.PullRequest {
+		ref = "PR " + ref
+	}
+	return fmt.Sprintf("%s %s", ref, i.Title)
+}
+
+// Summary describes an issue along with its metadata for the end of game
+// report.
+func (i issueEntry) Summary() string {
+	details := []string{}
+	if i.Author != "" {
+		details = append(details, "@"+i.Author)
+	}
+	if len(i.Labels) > 0 {
+		details = append(details, strings.Join(i.Labels, ", "))
+	}
+	if i.Comments > 0 {
+		details = append(details, fmt.Sprintf("%d comments", i.Comments))
+	}
+	if i.Reactions > 0 {
+		details = append(details, fmt.Sprintf("%d reactions", i.Reactions))
+	}
+	if len(details) == 0 {
+		return i.String()
+	}
+	return fmt.Sprintf("%s (%s)", i.String(), strings.Join(details, "; "))
+}
+
+// ghSource fetches game data from the GitHub API.
+type ghSource struct {
+	Filter  issueFilter
+	Commits commitRange
+	Pager   pager
+}
+
+func (gs ghSource) Issues(repo string) ([]issueEntry, error) {
+	return collectIssues(func(send func([]issueEntry)) error {
+		return gs.StreamIssues(repo, send)
+	})
+}
+
+func (gs ghSource) PullRequests(repo string) ([]issueEntry, error) {
+	return collectIssues(func(send func([]issueEntry)) error {
+		return gs.StreamPullRequests(repo, send)
+	})
+}
+
+func (gs ghSource) StreamIssues(repo string, send func([]issueEntry)) error {
+	return fetchIssues(repo, gs.Filter, gs.Pager, send)
+}
+
+func (gs ghSource) StreamPullRequests(repo string, send func([]issueEntry)) error {
+	return fetchPullRequests(repo, gs.Filter, gs.Pager, send)
+}
+
+func (gs ghSource) SHAs(repo string) ([]string, error) {
+	return getSHAs(repo, gs.Commits, gs.Pager)
+}
+
+// gitSource reads game data from the git repository in the current working
+// directory without touching the network. Commit subjects stand in for
+// issues since a plain clone has none.
+type gitSource struct {
+	Commits commitRange
+}
+
+func (gitSource) Issues(repo string) ([]issueEntry, error) {
+	return getLocalSubjects()
+}
+
+func (gitSource) PullRequests(repo string) ([]issueEntry, error) {
+	return []issueEntry{}, nil
+}
+
+func (gs gitSource) SHAs(repo string) ([]string, error) {
+	return getLocalSHAs(gs.Commits)
+}
+
+// fileSource serves game data from a fixture file loaded with --data.
+type fileSource struct {
+	fixture *fixture
+}
+
+func (fs fileSource) Issues(repo string) ([]issueEntry, error) {
+	return fs.fixture.Issues, nil
+}
+
+func (fs fileSource) PullRequests(repo string) ([]issueEntry, error) {
+	out := []issueEntry{}
+	for _, pr := range fs.fixture.PullRequests {
+		pr.PullRequest = true
+		out = append(out, pr)
+	}
+	return out, nil
+}
+
+func (fs fileSource) SHAs(repo string) ([]string, error) {
+	return fs.fixture.Commits, nil
+}